@@ -0,0 +1,58 @@
+package main
+
+import (
+	"net/url"
+	"strings"
+
+	"github.com/charmbracelet/ssh"
+)
+
+// accessibleCommand toggles accessible mode, or sets it with "on"/"off".
+func accessibleCommand(ctx *CommandContext) (string, error) {
+	t := ctx.Catalog
+	on := !ctx.Styles.Accessible
+	switch {
+	case len(ctx.Args) > 1:
+		return "", &UsageError{t.T("accessible.usage")}
+	case len(ctx.Args) == 1:
+		v, ok := parseSwitch(ctx.Args[0])
+		if !ok {
+			return "", &UsageError{t.T("accessible.invalid", ctx.Args[0])}
+		}
+		on = v
+	}
+	ctx.SetAccessible(on)
+
+	if on {
+		return t.T("accessible.on"), nil
+	}
+	st := ctx.Styles
+	return st.Label(t.T("accessible.off")) + "\n" + st.Dim(t.T("accessible.off_hint")), nil
+}
+
+// parseSwitch parses an on/off value as given to commands or environment
+// variables.
+func parseSwitch(v string) (on, ok bool) {
+	switch strings.ToLower(strings.TrimSpace(v)) {
+	case "1", "on", "yes", "true":
+		return true, true
+	case "0", "off", "no", "false":
+		return false, true
+	}
+	return false, false
+}
+
+// SSHAccessible reports whether an SSH client asked for accessible mode by
+// sending ACCESSIBLE=1 (ssh -o SendEnv=ACCESSIBLE).
+func SSHAccessible(s ssh.Session) bool {
+	on, _ := parseSwitch(lookupEnv(s.Environ(), "ACCESSIBLE"))
+	return on
+}
+
+// WebSocketAccessible reports whether a WebSocket client asked for accessible
+// mode in the handshake query (?accessible=1). Clients can also switch it
+// later with a "capabilities" message.
+func WebSocketAccessible(query url.Values) bool {
+	on, _ := parseSwitch(query.Get("accessible"))
+	return on
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"strings"
+
+	"github.com/charmbracelet/ssh"
+)
+
+// adminKeys are the fingerprints of the SSH keys allowed to run admin
+// commands, from ADMIN_KEYS (comma-separated, as printed by
+// `ssh-keygen -lf key.pub`).
+var adminKeys = strings.Split(envOr("ADMIN_KEYS", ""), ",")
+
+// IsAdmin reports whether key is the fingerprint of an admin's SSH key.
+func IsAdmin(key string) bool {
+	if key == "" {
+		return false
+	}
+	for _, k := range adminKeys {
+		if strings.TrimSpace(k) == key {
+			return true
+		}
+	}
+	return false
+}
+
+// SSHKeyFingerprint returns the SHA256 fingerprint of the public key an SSH
+// client signed in with, in ssh-keygen's format, or "" when it signed in
+// another way.
+func SSHKeyFingerprint(s ssh.Session) string {
+	key := s.PublicKey()
+	if key == nil {
+		return ""
+	}
+	sum := sha256.Sum256(key.Marshal())
+	return "SHA256:" + base64.RawStdEncoding.EncodeToString(sum[:])
+}
+
+// shortFingerprint shortens a key fingerprint for display.
+func shortFingerprint(key string) string {
+	if len(key) <= 19 {
+		return key
+	}
+	return key[:19] + "…"
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"net/http"
+
+	"github.com/charmbracelet/log"
+)
+
+// APIHandler serves portfolio documents over HTTP so the website and scripts
+// can consume the same content as the TUI: GET /api/{command}?format=json.
+// JSON is the default format; ?lang= or Accept-Language picks the language.
+// Commands with flags of their own take them as parameters too
+// (/api/projects?tag=go).
+func APIHandler(logger *log.Logger, registry *SessionRegistry) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		name := r.PathValue("command")
+		cmd, ok := FindCommand(name)
+		if !ok || cmd.Document == nil {
+			http.Error(w, "unknown command", http.StatusNotFound)
+			return
+		}
+
+		format := FormatJSON
+		if v := r.URL.Query().Get("format"); v != "" {
+			f, err := ParseFormat(v)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			format = f
+		}
+
+		st := NewStyles(MustTheme(DefaultTheme), nil)
+		t := CatalogFor(HTTPLocale(r))
+		flags := make(map[string]string, len(cmd.Flags))
+		for _, name := range cmd.Flags {
+			flags[name] = r.URL.Query().Get(name)
+		}
+		doc, err := cmd.Document(&CommandContext{User: "guest", Format: format, Styles: st, Catalog: t, Flags: flags, Sessions: registry})
+		if err != nil {
+			logger.Error("Failed to build document", "command", name, "error", err)
+			http.Error(w, "internal error", http.StatusInternalServerError)
+			return
+		}
+		body, err := RenderDocument(doc, format, st, t)
+		if err != nil {
+			logger.Error("Failed to render document", "command", name, "format", format, "error", err)
+			http.Error(w, "internal error", http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", format.ContentType())
+		w.Header().Set("Access-Control-Allow-Origin", "*")
+		_, _ = w.Write([]byte(body))
+	}
+}
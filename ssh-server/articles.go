@@ -0,0 +1,186 @@
+package main
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+)
+
+// articlesDocument lists the articles of my blog's feed, newest first, or
+// shows one of them when given its name or number (`articles 1`). In the
+// TUI every listed article is a choice that opens it.
+func articlesDocument(ctx *CommandContext) (Document, error) {
+	t := ctx.Catalog
+	list, err := LoadArticles()
+	if err != nil {
+		return nil, errors.New(t.T("articles.unavailable"))
+	}
+	c, err := LoadContentFor(t.Locale)
+	if err != nil {
+		return nil, err
+	}
+
+	switch len(ctx.Args) {
+	case 0:
+	case 1:
+		name := ctx.Args[0]
+		if n, err := strconv.Atoi(name); err == nil && n >= 1 && n <= len(list) {
+			return articleView{Article: list[n-1], width: markdownWidth(ctx.Width)}, nil
+		}
+		for _, a := range list {
+			if a.Slug() == strings.TrimSuffix(name, ".md") {
+				return articleView{Article: a, width: markdownWidth(ctx.Width)}, nil
+			}
+		}
+		return nil, &UsageError{t.T("articles.unknown", name)}
+	default:
+		return nil, &UsageError{t.T("articles.usage")}
+	}
+
+	for _, a := range list {
+		ctx.AddChoice(a.Title, "articles", a.Slug())
+	}
+	return articlesView{list: list, site: c.Profile.Articles}, nil
+}
+
+// articlesView is the list of articles. It is encoded as the bare list.
+type articlesView struct {
+	list ArticleList
+	site string // where the articles are published, from the profile
+}
+
+// MarshalJSON encodes the list.
+func (v articlesView) MarshalJSON() ([]byte, error) {
+	return jsonList(v.list)
+}
+
+// MarshalYAML encodes the list.
+func (v articlesView) MarshalYAML() (interface{}, error) {
+	if v.list == nil {
+		return []Article{}, nil
+	}
+	return v.list, nil
+}
+
+// hint tells how to open an article and where to read the rest.
+func (v articlesView) hint(t *Catalog) string {
+	hint := t.T("articles.hint")
+	if v.site != "" {
+		hint += " " + t.T("articles.more", v.site)
+	}
+	return hint
+}
+
+// Styled renders one entry per article with its date and summary.
+func (v articlesView) Styled(st *Styles, t *Catalog) string {
+	var sb strings.Builder
+
+	sb.WriteString(st.Header(strings.ToUpper(t.T("articles.heading"))))
+	sb.WriteString("\n\n")
+	if len(v.list) == 0 {
+		sb.WriteString(st.Dim(t.T("articles.none")))
+		return sb.String()
+	}
+
+	for _, a := range v.list {
+		sb.WriteString(st.Label(a.Title) + "  " + st.Dim(a.Date.Format("2006-01-02")) + "\n")
+		if a.Summary != "" {
+			sb.WriteString(st.Content.Render(RenderMarkdown(a.Summary, st, 56)) + "\n")
+		} else {
+			sb.WriteString("\n")
+		}
+	}
+	sb.WriteString(st.Dim(v.hint(t)))
+
+	return sb.String()
+}
+
+// Plain renders the list without styling.
+func (v articlesView) Plain(t *Catalog) string {
+	var sb strings.Builder
+
+	sb.WriteString(strings.ToUpper(t.T("articles.heading")) + "\n")
+	if len(v.list) == 0 {
+		sb.WriteString("\n" + t.T("articles.none") + "\n")
+		return sb.String()
+	}
+	for i, a := range v.list {
+		sb.WriteString("\n" + strconv.Itoa(i+1) + ". " + a.Title + "\n")
+		sb.WriteString(a.Date.Format("2006-01-02") + " | " + a.Slug() + "\n")
+		if a.Summary != "" {
+			sb.WriteString(RenderMarkdown(a.Summary, nil, 0) + "\n")
+		}
+	}
+
+	return sb.String()
+}
+
+// Accessible renders each article as labeled lines.
+func (v articlesView) Accessible(t *Catalog) string {
+	var sb strings.Builder
+
+	sb.WriteString(strings.ToUpper(t.T("articles.heading")) + ", " + t.T("articles.count", len(v.list)) + "\n")
+	for _, a := range v.list {
+		sb.WriteString("\n" + t.T("field.title") + " " + a.Title + "\n")
+		sb.WriteString(t.T("date.date") + " " + longDate(t, a.Date) + "\n")
+		if a.Summary != "" {
+			sb.WriteString(RenderMarkdown(a.Summary, nil, 0) + "\n")
+		}
+	}
+	if len(v.list) > 0 {
+		sb.WriteString("\n" + v.hint(t) + "\n")
+	}
+
+	return sb.String()
+}
+
+// Markdown renders the list as Markdown.
+func (v articlesView) Markdown(t *Catalog) string {
+	var sb strings.Builder
+
+	sb.WriteString("# " + t.T("articles.heading") + "\n")
+	for _, a := range v.list {
+		sb.WriteString("\n## [" + a.Title + "](" + markdownURL(a.URL) + ")\n\n")
+		sb.WriteString("_" + a.Date.Format("2006-01-02") + "_\n")
+		if a.Summary != "" {
+			sb.WriteString("\n" + a.Summary + "\n")
+		}
+	}
+
+	return sb.String()
+}
+
+// Markdown renders the article: its full body when the feed has one, else
+// the summary. It is also the article's file in ~/articles.
+func (a Article) Markdown() string {
+	var sb strings.Builder
+	sb.WriteString("# " + a.Title + "\n\n")
+	sb.WriteString("_" + a.Date.Format("2006-01-02") + "_\n\n")
+	body := a.Body
+	if body == "" {
+		body = a.Summary
+	}
+	sb.WriteString(body + "\n\n<" + markdownURL(a.URL) + ">\n")
+	return sb.String()
+}
+
+// articleView is one article, wrapped to the terminal.
+type articleView struct {
+	Article `yaml:",inline"`
+	width   int
+}
+
+// Styled renders the article's Markdown.
+func (v articleView) Styled(st *Styles, t *Catalog) string {
+	return RenderMarkdown(v.Article.Markdown(), st, v.width) + "\n\n" + st.Dim(t.T("articles.back"))
+}
+
+// Plain renders the article without markup.
+func (v articleView) Plain(t *Catalog) string {
+	return RenderMarkdown(v.Article.Markdown(), nil, 0) + "\n"
+}
+
+// Markdown renders the article as Markdown.
+func (v articleView) Markdown(t *Catalog) string {
+	return v.Article.Markdown()
+}
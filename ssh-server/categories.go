@@ -0,0 +1,91 @@
+package main
+
+import (
+	"sort"
+	"strings"
+)
+
+// Category groups commands in the menu, help and prompt completion.
+// Commands refer to a category by ID; how it is titled, ordered and shown is
+// metadata here rather than in each renderer.
+type Category struct {
+	ID     string
+	Title  string // English title; translations live in the catalog under "category.<id>"
+	Order  int    // sections are shown in ascending order
+	Icon   string // shown before the title in the styled menu
+	Hidden bool   // left out of the menu, help and completion, but still runnable by name
+}
+
+// Categories lists the known command categories.
+var Categories = []Category{
+	{ID: "portfolio", Title: "Portfolio", Order: 10, Icon: "◆"},
+	{ID: "files", Title: "Files", Order: 20, Icon: "▤"},
+	{ID: "community", Title: "Community", Order: 30, Icon: "✎"},
+	{ID: "system", Title: "System", Order: 90, Icon: "⚙"},
+	{ID: "admin", Title: "Admin", Order: 95, Icon: "⚑", Hidden: true},
+}
+
+// FindCategory returns the category with the given ID. Commands in a
+// category that isn't registered still get a section of their own, titled
+// after the ID and placed between the known ones.
+func FindCategory(id string) Category {
+	for _, c := range Categories {
+		if c.ID == id {
+			return c
+		}
+	}
+	title := id
+	if title == "" {
+		title = "other"
+	}
+	return Category{ID: id, Title: strings.ToUpper(title[:1]) + title[1:], Order: 50, Icon: "•"}
+}
+
+// Section is a category together with its commands, in display order.
+type Section struct {
+	Category Category
+	Commands []Command
+}
+
+// Sections groups cmds by category, ordered by Category.Order and then
+// title, leaving out hidden categories. Commands keep their registry order
+// within a section.
+func Sections(cmds []Command) []Section {
+	var sections []Section
+	index := map[string]int{}
+	for _, cmd := range cmds {
+		i, ok := index[cmd.Category]
+		if !ok {
+			cat := FindCategory(cmd.Category)
+			if cat.Hidden {
+				continue
+			}
+			i = len(sections)
+			index[cmd.Category] = i
+			sections = append(sections, Section{Category: cat})
+		}
+		sections[i].Commands = append(sections[i].Commands, cmd)
+	}
+	sort.SliceStable(sections, func(i, j int) bool {
+		a, b := sections[i].Category, sections[j].Category
+		if a.Order != b.Order {
+			return a.Order < b.Order
+		}
+		return a.Title < b.Title
+	})
+	return sections
+}
+
+// CompleteCommand returns the visible commands whose names start with
+// prefix, in display order.
+func CompleteCommand(cmds []Command, prefix string) []Command {
+	var matches []Command
+	for _, section := range Sections(cmds) {
+		for _, cmd := range section.Commands {
+			if strings.HasPrefix(cmd.Name, prefix) {
+				matches = append(matches, cmd)
+			}
+		}
+	}
+	return matches
+}
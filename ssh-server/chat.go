@@ -0,0 +1,405 @@
+package main
+
+import (
+	"errors"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// Chat limits. A visitor may send chatBurst messages per chatBurstWindow.
+const (
+	chatMaxMessage  = 300 // runes
+	chatMaxNick     = 20
+	chatScrollback  = 200 // lines kept
+	chatBurst       = 5
+	chatBurstWindow = 10 * time.Second
+)
+
+// ErrNotInChat is returned for a session that didn't join the room.
+var ErrNotInChat = errors.New("not in the chat room")
+
+// ChatMsg tells a TUI in the chat room that its lines changed. The TUI
+// reads them from the room, so they show in order however the messages
+// arrive.
+type ChatMsg struct{}
+
+// ChatLineKind tells messages from join and leave notices.
+type ChatLineKind int
+
+const (
+	ChatMessage ChatLineKind = iota
+	ChatJoin
+	ChatLeave
+)
+
+// ChatLine is a line of the chat room's scrollback.
+type ChatLine struct {
+	Time time.Time
+	Nick string
+	Text string
+	Kind ChatLineKind
+}
+
+// chatMember is a session in the chat room.
+type chatMember struct {
+	session *Session
+	nick    string
+	joined  int         // order of joining
+	sent    []time.Time // recent messages, for flood control
+}
+
+// ChatRoom is the room shared by every visitor in the `chat` view. Lines
+// are kept in a bounded scrollback and members are told of new ones
+// through their sessions.
+type ChatRoom struct {
+	mu      sync.Mutex
+	members map[string]*chatMember // by session ID
+	joins   int                    // members that ever joined
+	lines   []ChatLine
+	now     func() time.Time
+}
+
+// NewChatRoom creates an empty room.
+func NewChatRoom() *ChatRoom {
+	return &ChatRoom{members: make(map[string]*chatMember), now: time.Now}
+}
+
+// chatNick derives a nickname from an SSH login name, keeping letters,
+// digits and a few separators. Web visitors and names with nothing left
+// get the session's anonymous name.
+func chatNick(user string, s *Session) string {
+	nick := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_', r == '.':
+			return r
+		}
+		return -1
+	}, sanitizeLine(user, chatMaxNick))
+	if nick == "" || nick == "guest" {
+		nick = s.Name
+	}
+	return maskProfanity(nick)
+}
+
+// Join adds s to the room under a nickname derived from user, numbered
+// when taken, and announces it. Joining again keeps the nickname.
+func (c *ChatRoom) Join(s *Session, user string) string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if m, ok := c.members[s.ID]; ok {
+		return m.nick
+	}
+
+	base := chatNick(user, s)
+	nick := base
+	for n := 2; c.taken(nick); n++ {
+		nick = base + "-" + strconv.Itoa(n)
+	}
+	c.joins++
+	c.members[s.ID] = &chatMember{session: s, nick: nick, joined: c.joins}
+	c.add(ChatLine{Nick: nick, Kind: ChatJoin})
+	return nick
+}
+
+// taken reports whether a member uses nick. c.mu must be held.
+func (c *ChatRoom) taken(nick string) bool {
+	for _, m := range c.members {
+		if strings.EqualFold(m.nick, nick) {
+			return true
+		}
+	}
+	return false
+}
+
+// Leave removes the session with the given ID from the room and announces
+// it. Sessions that aren't in the room are ignored.
+func (c *ChatRoom) Leave(id string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	m, ok := c.members[id]
+	if !ok {
+		return
+	}
+	delete(c.members, id)
+	c.add(ChatLine{Nick: m.nick, Kind: ChatLeave})
+}
+
+// Say sends text from the session with the given ID. Escape sequences and
+// control characters are removed first. Members sending too fast get a
+// *RateLimitError.
+func (c *ChatRoom) Say(id, text string) error {
+	text = maskProfanity(sanitizeLine(text, chatMaxMessage))
+	if text == "" {
+		return ErrEmptyMessage
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	m, ok := c.members[id]
+	if !ok {
+		return ErrNotInChat
+	}
+	now := c.now()
+	recent := m.sent[:0]
+	for _, t := range m.sent {
+		if now.Sub(t) < chatBurstWindow {
+			recent = append(recent, t)
+		}
+	}
+	m.sent = recent
+	if len(m.sent) >= chatBurst {
+		return &RateLimitError{Wait: m.sent[0].Add(chatBurstWindow).Sub(now)}
+	}
+	m.sent = append(m.sent, now)
+	c.add(ChatLine{Nick: m.nick, Text: text})
+	return nil
+}
+
+// add appends line to the scrollback and tells every member. c.mu must be
+// held.
+func (c *ChatRoom) add(line ChatLine) {
+	line.Time = c.now()
+	c.lines = append(c.lines, line)
+	if n := len(c.lines) - chatScrollback; n > 0 {
+		c.lines = append([]ChatLine(nil), c.lines[n:]...)
+	}
+	for _, m := range c.members {
+		go m.session.Send(ChatMsg{})
+	}
+}
+
+// Lines returns the scrollback, oldest first.
+func (c *ChatRoom) Lines() []ChatLine {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]ChatLine(nil), c.lines...)
+}
+
+// Nicks returns the nicknames of the members, in the order they joined.
+func (c *ChatRoom) Nicks() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	members := make([]*chatMember, 0, len(c.members))
+	for _, m := range c.members {
+		members = append(members, m)
+	}
+	sort.Slice(members, func(i, j int) bool { return members[i].joined < members[j].joined })
+	nicks := make([]string, len(members))
+	for i, m := range members {
+		nicks[i] = m.nick
+	}
+	return nicks
+}
+
+// chatCommand opens the chat room in the TUI. Elsewhere it prints the
+// latest lines, as the room needs a live session to take part.
+func chatCommand(ctx *CommandContext) (string, error) {
+	t := ctx.Catalog
+	if ctx.Sessions == nil {
+		return "", errors.New(t.T("chat.unavailable"))
+	}
+	ctx.Chat = true
+
+	var sb strings.Builder
+	sb.WriteString(strings.ToUpper(t.T("chat.heading")) + "\n\n")
+	lines := ctx.Sessions.Chat().Lines()
+	for _, l := range lines[max(len(lines)-20, 0):] {
+		sb.WriteString(chatLineText(t, l) + "\n")
+	}
+	sb.WriteString("\n" + t.T("chat.exec_hint"))
+	return sb.String(), nil
+}
+
+// chatLineText renders l as plain text.
+func chatLineText(t *Catalog, l ChatLine) string {
+	stamp := l.Time.Format("15:04")
+	switch l.Kind {
+	case ChatJoin:
+		return stamp + " → " + t.T("chat.joined", l.Nick)
+	case ChatLeave:
+		return stamp + " ← " + t.T("chat.left", l.Nick)
+	}
+	return stamp + " " + l.Nick + ": " + l.Text
+}
+
+// openChat joins the chat room and shows it instead of the output of the
+// command that opened it.
+func (m *Model) openChat() {
+	room := m.session.Registry().Chat()
+	m.chat = room
+	m.chatNick = room.Join(m.session, m.user)
+	m.chatLines = room.Lines()
+	m.chatInput = ""
+	m.chatStatus = ""
+	m.chatScroll = 0
+	m.mode = ChatMode
+}
+
+// leaveChat leaves the chat room for the menu.
+func (m *Model) leaveChat() {
+	if m.chat != nil {
+		m.chat.Leave(m.session.ID)
+	}
+	m.chat = nil
+	m.chatLines = nil
+	m.chatInput = ""
+	m.mode = MenuMode
+}
+
+// updateChat handles keys in the chat room. Typing goes to the message,
+// Enter sends it, the arrows and page keys scroll back and Esc leaves.
+func (m Model) updateChat(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.Type {
+	case tea.KeyCtrlC:
+		m.leaveChat()
+		return m, tea.Quit
+	case tea.KeyEsc:
+		m.leaveChat()
+	case tea.KeyEnter:
+		m.sendChat()
+	case tea.KeyBackspace:
+		if r := []rune(m.chatInput); len(r) > 0 {
+			m.chatInput = string(r[:len(r)-1])
+		}
+	case tea.KeyUp:
+		m.scrollChat(1)
+	case tea.KeyDown:
+		m.scrollChat(-1)
+	case tea.KeyPgUp:
+		m.scrollChat(max(m.chatRows()-1, 1))
+	case tea.KeyPgDown:
+		m.scrollChat(-max(m.chatRows()-1, 1))
+	case tea.KeySpace, tea.KeyRunes:
+		text := " "
+		if msg.Type == tea.KeyRunes {
+			text = string(msg.Runes)
+		}
+		// Pasted text may bring escape sequences; Say cleans the rest
+		value := []rune(m.chatInput + ansi.Strip(text))
+		m.chatInput = string(value[:min(len(value), chatMaxMessage)])
+	}
+	return m, nil
+}
+
+// sendChat says the typed message in the room.
+func (m *Model) sendChat() {
+	t := m.catalog
+	err := m.chat.Say(m.session.ID, m.chatInput)
+	var limit *RateLimitError
+	switch {
+	case errors.Is(err, ErrEmptyMessage):
+		return
+	case errors.As(err, &limit):
+		// Keep what was typed, to send once allowed
+		m.chatStatus = t.T("chat.flood", int(limit.Wait.Seconds())+1)
+		return
+	case err != nil:
+		m.chatStatus = t.T("error.prefix", err.Error())
+		return
+	}
+	m.chatInput = ""
+	m.chatStatus = ""
+	m.chatScroll = 0
+	m.chatLines = m.chat.Lines()
+}
+
+// scrollChat scrolls back n wrapped lines, or forward when n is negative,
+// staying within the scrollback.
+func (m *Model) scrollChat(n int) {
+	m.chatScroll = max(min(m.chatScroll+n, len(m.chatWrapped())-m.chatRows()), 0)
+}
+
+// chatRows returns how many lines of scrollback fit on screen.
+func (m Model) chatRows() int {
+	if m.height <= 0 {
+		return chatScrollback
+	}
+	fixed := m.renderTop() + m.renderChatHeader() + m.renderChatFooter()
+	return max(m.height-strings.Count(fixed, "\n")-1, 1)
+}
+
+// renderChatHeader renders the title and who is in the room.
+func (m Model) renderChatHeader() string {
+	st, t := m.styles, m.catalog
+	nicks := m.chat.Nicks()
+	var sb strings.Builder
+	sb.WriteString(st.Header(strings.ToUpper(t.T("chat.heading"))) + "\n")
+	sb.WriteString(st.Dim(t.T("chat.members", len(nicks), strings.Join(nicks, ", "))) + "\n\n")
+	return sb.String()
+}
+
+// chatWrapped renders the scrollback wrapped to the terminal.
+func (m Model) chatWrapped() []string {
+	st, t := m.styles, m.catalog
+	width := max(m.width-2, 20)
+
+	var lines []string
+	for _, l := range m.chatLines {
+		var text string
+		switch {
+		case st.Accessible:
+			text = chatLineText(t, l)
+		case l.Kind != ChatMessage:
+			text = st.Dim(chatLineText(t, l))
+		default:
+			nick := st.Label(l.Nick)
+			if l.Nick == m.chatNick {
+				nick = st.Prompt.Render(l.Nick)
+			}
+			text = st.Dim(l.Time.Format("15:04")) + " " + nick + " " + l.Text
+		}
+		lines = append(lines, strings.Split(ansi.Wrap(text, width, " "), "\n")...)
+	}
+	return lines
+}
+
+// renderChat renders the room, ending at the latest line unless the
+// visitor scrolled back.
+func (m Model) renderChat() string {
+	st, t := m.styles, m.catalog
+	lines := m.chatWrapped()
+	rows := m.chatRows()
+	scroll := min(m.chatScroll, max(len(lines)-rows, 0))
+	to := len(lines) - scroll
+	from := max(to-rows, 0)
+	view := lines[from:to]
+	if len(view) == 0 {
+		view = []string{st.Dim(t.T("chat.empty"))}
+	}
+	for len(view) < rows {
+		view = append([]string{""}, view...)
+	}
+	return m.renderChatHeader() + strings.Join(view, "\n")
+}
+
+// renderChatFooter renders the message being typed, flood warnings and the
+// key help.
+func (m Model) renderChatFooter() string {
+	st, t := m.styles, m.catalog
+	var sb strings.Builder
+	sb.WriteString("\n")
+	if m.chatStatus != "" {
+		sb.WriteString(st.Error.Render(m.chatStatus))
+	} else if m.chatScroll > 0 {
+		sb.WriteString(st.Dim(t.T("chat.scrolled")))
+	}
+	sb.WriteString("\n")
+	if st.Accessible {
+		sb.WriteString(t.T("chat.input", m.chatNick) + " " + m.chatInput + "\n")
+	} else {
+		sb.WriteString(st.Prompt.Render(m.chatNick+" ›") + " " + m.chatInput + "█\n")
+		back := st.Prompt.Render(t.T("content.back"))
+		if m.hover == "back" {
+			back = st.LinkHover.Inherit(st.Prompt).Render(t.T("content.back"))
+		}
+		sb.WriteString("  " + zoneMark("back", back) + "\n")
+	}
+	sb.WriteString(st.Help.Render(t.T("chat.help")))
+	return sb.String()
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestChatRoom(t *testing.T) {
+	c := NewChatRoom()
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	c.now = func() time.Time { return now }
+
+	ada := NewSession("a", TransportSSH, "ada", "", nil)
+	ada2 := NewSession("b", TransportSSH, "Ada", "", nil)
+	web := NewSession("c", TransportWebSocket, "guest", "", nil)
+	if nick := c.Join(ada, "ada"); nick != "ada" {
+		t.Errorf("nick = %q, want the SSH user name", nick)
+	}
+	if nick := c.Join(ada2, "Ada"); nick != "Ada-2" {
+		t.Errorf("nick = %q, want a numbered one when taken", nick)
+	}
+	if nick := c.Join(web, "guest"); nick != web.Name {
+		t.Errorf("nick = %q, want the anonymous name %q", nick, web.Name)
+	}
+
+	if err := c.Say("a", "hi \x1b]52;c;aGk=\x07there\x1b[2J"); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Say("a", "\x1b[31m"); !errors.Is(err, ErrEmptyMessage) {
+		t.Errorf("only escapes: err = %v, want ErrEmptyMessage", err)
+	}
+	if err := c.Say("x", "hi"); !errors.Is(err, ErrNotInChat) {
+		t.Errorf("outside the room: err = %v, want ErrNotInChat", err)
+	}
+	lines := c.Lines()
+	if l := lines[len(lines)-1]; l.Nick != "ada" || l.Text != "hi there" {
+		t.Errorf("line = %+v, want the message without escapes", l)
+	}
+
+	// A burst is allowed, then the sender waits for the window to pass
+	for i := 1; i < chatBurst; i++ {
+		if err := c.Say("a", "more"); err != nil {
+			t.Fatalf("message %d: %v", i+1, err)
+		}
+	}
+	var limit *RateLimitError
+	if err := c.Say("a", "flood"); !errors.As(err, &limit) || limit.Wait != chatBurstWindow {
+		t.Errorf("over the burst: err = %v, want a wait of %s", err, chatBurstWindow)
+	}
+	now = now.Add(chatBurstWindow)
+	if err := c.Say("a", "later"); err != nil {
+		t.Errorf("after the window: %v", err)
+	}
+
+	c.Leave("b")
+	if nicks := c.Nicks(); len(nicks) != 2 || nicks[0] != "ada" || nicks[1] != web.Name {
+		t.Errorf("nicks = %v, want ada and the web visitor in joining order", nicks)
+	}
+	for range chatScrollback {
+		c.Say("c", "spam")
+		now = now.Add(chatBurstWindow)
+	}
+	if n := len(c.Lines()); n != chatScrollback {
+		t.Errorf("%d lines kept, want %d", n, chatScrollback)
+	}
+}
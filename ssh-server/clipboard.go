@@ -0,0 +1,73 @@
+package main
+
+import (
+	"io"
+	"strings"
+
+	"github.com/aymanbagabas/go-osc52/v2"
+)
+
+// Clipboard puts text on the visitor's clipboard. Each transport provides
+// its own; it is nil where the clipboard can't be reached, such as exec
+// commands without a terminal.
+type Clipboard func(text string) error
+
+// OSC52Clipboard copies through the visitor's terminal with an OSC 52
+// sequence written to w. Inside tmux or screen, whose TERM the client
+// reports, the sequence is wrapped so the multiplexer passes it on.
+func OSC52Clipboard(w io.Writer, term string) Clipboard {
+	return func(text string) error {
+		seq := osc52.New(text)
+		switch {
+		case strings.HasPrefix(term, "tmux"):
+			seq = seq.Tmux()
+		case strings.HasPrefix(term, "screen"):
+			seq = seq.Screen()
+		}
+		_, err := seq.WriteTo(w)
+		return err
+	}
+}
+
+// LinkValue is what copying a link puts on the clipboard: the address of an
+// email link, or the absolute URL of any other.
+func LinkValue(l Link) string {
+	if !strings.Contains(l.URL, "://") && strings.Contains(l.URL, "@") {
+		return l.URL
+	}
+	return markdownURL(l.URL)
+}
+
+// copyCommand copies one of the links to the clipboard (`copy email`).
+// Without a clipboard it prints the value instead, so it can be piped:
+// `ssh genar.me copy email | pbcopy`.
+func copyCommand(ctx *CommandContext) (string, error) {
+	st, t := ctx.Styles, ctx.Catalog
+	c, err := LoadContentFor(t.Locale)
+	if err != nil {
+		return "", err
+	}
+
+	ids := make([]string, 0, len(c.Links))
+	for _, link := range c.Links {
+		ids = append(ids, link.ID)
+	}
+	if len(ctx.Args) != 1 {
+		return "", &UsageError{t.T("copy.usage", strings.Join(ids, ", "))}
+	}
+
+	for _, link := range c.Links {
+		if link.ID != ctx.Args[0] {
+			continue
+		}
+		value := LinkValue(link)
+		if ctx.Clipboard == nil {
+			return value, nil
+		}
+		if err := ctx.Clipboard(value); err != nil {
+			return "", err
+		}
+		return st.Label(t.T("copy.done", link.Name)) + " " + st.Value(value), nil
+	}
+	return "", &UsageError{t.T("copy.unknown", ctx.Args[0], strings.Join(ids, ", "))}
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"io"
+	"net/url"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/ssh"
+	"github.com/muesli/termenv"
+)
+
+// DetectColorProfile works out how many colors a client can display from the
+// terminal type and environment it reported. NO_COLOR always wins, then an
+// explicit COLORTERM, then what TERM implies.
+func DetectColorProfile(term string, getenv func(string) string) termenv.Profile {
+	if getenv("NO_COLOR") != "" {
+		return termenv.Ascii
+	}
+
+	switch strings.ToLower(getenv("COLORTERM")) {
+	case "truecolor", "24bit":
+		return termenv.TrueColor
+	}
+
+	term = strings.ToLower(term)
+	switch {
+	case term == "" || term == "dumb":
+		return termenv.Ascii
+	case strings.Contains(term, "truecolor"), strings.Contains(term, "24bit"),
+		strings.HasPrefix(term, "xterm-kitty"), strings.HasPrefix(term, "wezterm"),
+		strings.HasPrefix(term, "alacritty"), strings.HasPrefix(term, "foot"),
+		strings.HasPrefix(term, "contour"), strings.HasPrefix(term, "xterm-ghostty"):
+		return termenv.TrueColor
+	case strings.Contains(term, "256color"):
+		return termenv.ANSI256
+	}
+	return termenv.ANSI
+}
+
+// ParseColorProfile parses a profile name as sent by WebSocket clients:
+// truecolor, 256, 16 or none.
+func ParseColorProfile(name string) (termenv.Profile, bool) {
+	switch strings.ToLower(name) {
+	case "truecolor", "24bit", "16m":
+		return termenv.TrueColor, true
+	case "256", "ansi256", "256color":
+		return termenv.ANSI256, true
+	case "16", "ansi", "8":
+		return termenv.ANSI, true
+	case "none", "ascii", "no", "0":
+		return termenv.Ascii, true
+	}
+	return termenv.Ascii, false
+}
+
+// ColorProfileName returns a readable name for p, for logs.
+func ColorProfileName(p termenv.Profile) string {
+	switch p {
+	case termenv.TrueColor:
+		return "truecolor"
+	case termenv.ANSI256:
+		return "256"
+	case termenv.ANSI:
+		return "16"
+	}
+	return "none"
+}
+
+// NewSessionRenderer creates a lipgloss renderer bound to one session, so
+// styles degrade to what that client supports instead of following the
+// server's own stdout.
+func NewSessionRenderer(w io.Writer, p termenv.Profile) *lipgloss.Renderer {
+	r := lipgloss.NewRenderer(w, termenv.WithProfile(p))
+	r.SetColorProfile(p)
+	// Themes are designed for dark terminals; setting this also stops
+	// lipgloss from querying the client's background color.
+	r.SetHasDarkBackground(true)
+	return r
+}
+
+// SSHColorProfile detects the color profile of an SSH session from its PTY
+// terminal type and the environment the client sent.
+func SSHColorProfile(s ssh.Session) termenv.Profile {
+	pty, _, ok := s.Pty()
+	if !ok {
+		return termenv.Ascii
+	}
+	return DetectColorProfile(pty.Term, func(key string) string {
+		return lookupEnv(s.Environ(), key)
+	})
+}
+
+// WebSocketColorProfile detects the color profile of a WebSocket client from
+// the handshake query (?color=256, or ?term=...&colorterm=...&no_color=1).
+// xterm.js supports truecolor, so that is the default.
+func WebSocketColorProfile(query url.Values) termenv.Profile {
+	if p, ok := ParseColorProfile(query.Get("color")); ok {
+		return p
+	}
+	if !query.Has("term") && !query.Has("colorterm") && !query.Has("no_color") {
+		return termenv.TrueColor
+	}
+	return DetectColorProfile(query.Get("term"), func(key string) string {
+		return query.Get(strings.ToLower(key))
+	})
+}
+
+// lookupEnv finds key in a KEY=value environment list.
+func lookupEnv(environ []string, key string) string {
+	for _, kv := range environ {
+		if k, v, ok := strings.Cut(kv, "="); ok && k == key {
+			return v
+		}
+	}
+	return ""
+}
@@ -4,14 +4,111 @@ import (
 	"fmt"
 	"strings"
 	"time"
+
+	"github.com/charmbracelet/lipgloss"
 )
 
-// Command represents a terminal command
+// Command represents a terminal command. Portfolio commands set Document so
+// their content can be rendered in any Format; other commands set Execute.
 type Command struct {
 	Name        string
-	Description string
+	Description string // English description; translations live in the catalog under "cmd.<name>"
 	Category    string
-	Execute     func() string
+	Shortcut    rune // key that runs the command from the menu, 0 for none
+	Execute     func(ctx *CommandContext) (string, error)
+	Document    func(ctx *CommandContext) (Document, error)
+
+	// Document commands take no arguments besides --format unless they set
+	// Args, to get positional arguments in ctx.Args, or Flags, to name
+	// string flags of their own whose values they find in ctx.Flags.
+	Args  bool
+	Flags []string
+}
+
+// Run executes the command. Document commands accept --format and --json.
+func (c Command) Run(ctx *CommandContext) (string, error) {
+	if ctx.Styles == nil {
+		ctx.Styles = NewStyles(MustTheme(DefaultTheme), nil)
+	}
+	if ctx.Catalog == nil {
+		ctx.Catalog = CatalogFor(DefaultLocale)
+	}
+	if c.Document != nil {
+		return runDocument(c, ctx)
+	}
+	return c.Execute(ctx)
+}
+
+// UsageError is returned by a command called with arguments or flags it
+// doesn't take, as opposed to one that failed. Exec requests exit with
+// status 2 for it.
+type UsageError struct {
+	Msg string
+}
+
+func (e *UsageError) Error() string {
+	return e.Msg
+}
+
+// CommandContext carries per-invocation state into a command, whether it was
+// selected from the TUI menu or run as an SSH exec request.
+type CommandContext struct {
+	Args      []string          // arguments after the command name
+	User      string            // SSH login name, or "guest" for WebSocket visitors
+	Format    Format            // default output format, overridable with --format
+	Styles    *Styles           // the session's styles; commands may replace them
+	Catalog   *Catalog          // the session's language; commands may replace it
+	Clipboard Clipboard         // the visitor's clipboard, nil when out of reach
+	Width     int               // terminal width in cells, 0 when unknown
+	Images    ImageProtocol     // how pictures can be drawn, "" for none
+	Dir       string            // working directory of the file commands, "" for home; cd changes it
+	Key       string            // fingerprint of the SSH key the visitor signed in with, "" for none
+	Flags     map[string]string // values of the command's own flags, see Command.Flags
+	Choices   []Choice          // entries of the output the TUI lets the visitor pick and open
+	Form      *Form             // form the TUI shows instead of the output
+	Chat      bool              // the TUI opens the chat room instead of the output
+	Session   *Session          // the visitor's connection, nil for /api requests
+	Sessions  *SessionRegistry  // every open session, for presence; nil when unknown
+}
+
+// Choice is an entry in a command's output that opens another command line,
+// such as a project in the `projects` list opening its page.
+type Choice struct {
+	Label string   // text of the entry as it appears in the output
+	Args  []string // command line it opens, command name first
+}
+
+// AddChoice makes the entry showing label open the command line args.
+func (ctx *CommandContext) AddChoice(label string, args ...string) {
+	ctx.Choices = append(ctx.Choices, Choice{Label: label, Args: args})
+}
+
+// SetTheme switches the session to theme t. The caller picks up the new
+// styles from ctx.Styles once the command returns.
+func (ctx *CommandContext) SetTheme(t Theme) {
+	ctx.Styles = ctx.Styles.WithTheme(t)
+}
+
+// SetAccessible switches the session's accessible mode on or off, the same
+// way SetTheme switches themes.
+func (ctx *CommandContext) SetAccessible(on bool) {
+	ctx.Styles = ctx.Styles.WithAccessible(on)
+}
+
+// SetLocale switches the session's language. The caller picks up the new
+// catalog from ctx.Catalog once the command returns.
+func (ctx *CommandContext) SetLocale(l Locale) {
+	ctx.Catalog = CatalogFor(l)
+}
+
+// FindCommand looks up a command by name.
+func FindCommand(name string) (Command, bool) {
+	for _, cmd := range GetAllCommands() {
+		if cmd.Name == name {
+			return cmd, true
+		}
+	}
+	return Command{}, false
 }
 
 // GetAllCommands returns all available commands
@@ -22,253 +119,617 @@ func GetAllCommands() []Command {
 			Name:        "about",
 			Description: "Learn about me",
 			Category:    "portfolio",
-			Execute:     aboutCommand,
+			Shortcut:    'a',
+			Document:    aboutDocument,
 		},
 		{
 			Name:        "skills",
 			Description: "View my technical skills",
 			Category:    "portfolio",
-			Execute:     skillsCommand,
+			Shortcut:    's',
+			Document:    skillsDocument,
 		},
 		{
 			Name:        "experience",
 			Description: "View my work experience",
 			Category:    "portfolio",
-			Execute:     experienceCommand,
+			Shortcut:    'e',
+			Document:    experienceDocument,
 		},
 		{
 			Name:        "links",
 			Description: "View my social links",
 			Category:    "portfolio",
-			Execute:     linksCommand,
+			Shortcut:    'l',
+			Document:    linksDocument,
+		},
+		{
+			Name:        "vcard",
+			Description: "Get my contact card (vCard)",
+			Category:    "portfolio",
+			Shortcut:    'v',
+			Document:    vcardDocument,
+		},
+		{
+			Name:        "resume",
+			Description: "Get my CV as a JSON Resume",
+			Category:    "portfolio",
+			Shortcut:    'r',
+			Document:    resumeDocument,
+		},
+		{
+			Name:        "projects",
+			Description: "Browse my projects",
+			Category:    "portfolio",
+			Shortcut:    'p',
+			Document:    projectsDocument,
+			Args:        true,
+			Flags:       []string{"tag"},
+		},
+		{
+			Name:        "articles",
+			Description: "Read my articles",
+			Category:    "portfolio",
+			Document:    articlesDocument,
+			Args:        true,
+		},
+		{
+			Name:        "copy",
+			Description: "Copy a link to your clipboard",
+			Category:    "portfolio",
+			Execute:     copyCommand,
+		},
+		// File commands
+		{
+			Name:        "ls",
+			Description: "List files in a directory",
+			Category:    "files",
+			Execute:     lsCommand,
+		},
+		{
+			Name:        "cd",
+			Description: "Change directory",
+			Category:    "files",
+			Execute:     cdCommand,
+		},
+		{
+			Name:        "cat",
+			Description: "Show a file",
+			Category:    "files",
+			Execute:     catCommand,
+		},
+		{
+			Name:        "pwd",
+			Description: "Print the working directory",
+			Category:    "files",
+			Execute:     pwdCommand,
+		},
+		// Community commands
+		{
+			Name:        "guestbook",
+			Description: "Read and sign the guestbook",
+			Category:    "community",
+			Document:    guestbookDocument,
+			Args:        true,
+		},
+		{
+			Name:        "who",
+			Description: "See who else is browsing",
+			Category:    "community",
+			Document:    whoDocument,
+		},
+		{
+			Name:        "chat",
+			Description: "Talk with the other visitors",
+			Category:    "community",
+			Execute:     chatCommand,
+		},
+		{
+			Name:        "contact",
+			Description: "Send me a message",
+			Category:    "community",
+			Execute:     contactCommand,
 		},
 		// System commands
 		{
 			Name:        "help",
 			Description: "Show all available commands",
 			Category:    "system",
+			Shortcut:    'h',
 			Execute:     helpCommand,
 		},
 		{
 			Name:        "date",
 			Description: "Display current date and time",
 			Category:    "system",
+			Shortcut:    'd',
 			Execute:     dateCommand,
 		},
 		{
 			Name:        "whoami",
 			Description: "Display current user info",
 			Category:    "system",
+			Shortcut:    'w',
 			Execute:     whoamiCommand,
 		},
+		{
+			Name:        "theme",
+			Description: "List or switch color themes",
+			Category:    "system",
+			Shortcut:    't',
+			Execute:     themeCommand,
+		},
+		{
+			Name:        "accessible",
+			Description: "Toggle plain output for screen readers",
+			Category:    "system",
+			Execute:     accessibleCommand,
+		},
+		{
+			Name:        "lang",
+			Description: "List or switch languages",
+			Category:    "system",
+			Execute:     langCommand,
+		},
+		// Admin commands, for the keys in ADMIN_KEYS
+		{
+			Name:        "moderate",
+			Description: "Hide, show or delete guestbook entries",
+			Category:    "admin",
+			Execute:     moderateCommand,
+		},
+		{
+			Name:        "inbox",
+			Description: "Read contact messages and mark them read",
+			Category:    "admin",
+			Execute:     inboxCommand,
+		},
+	}
+}
+
+// aboutDocument returns the personal bio, with the avatar when the
+// terminal can show it
+func aboutDocument(ctx *CommandContext) (Document, error) {
+	c, err := LoadContentFor(ctx.Catalog.Locale)
+	if err != nil {
+		return nil, err
+	}
+	view := aboutView{Profile: c.Profile}
+	if cols := avatarColumns(ctx.Width); cols > 0 && ctx.Images != "" {
+		img, err := LoadAvatar()
+		if err != nil {
+			return nil, err
+		}
+		view.avatar = img.Render(cols, ctx.Images, ctx.Styles.ColorProfile())
+		view.beside = ctx.Images == ImageBlocks && ctx.Width >= cols+2+aboutWidth
 	}
+	return view, nil
 }
 
-// aboutCommand displays personal bio
-func aboutCommand() string {
+// aboutWidth is how wide the styled bio is, header box included.
+const aboutWidth = 64
+
+// avatarColumns sizes the avatar for a terminal width cells wide: a third
+// of the width, up to 32 cells, and none at all on narrow terminals.
+func avatarColumns(width int) int {
+	cols := min(width/3, 32)
+	if cols < 12 {
+		return 0
+	}
+	return cols
+}
+
+// aboutView is the bio with the avatar drawn for one terminal. Only the
+// styled rendering shows the picture; the other formats are the profile's.
+type aboutView struct {
+	Profile `yaml:",inline"`
+
+	avatar string // the rendered avatar, empty when not shown
+	beside bool   // the avatar goes left of the bio rather than above it
+}
+
+// Styled renders the bio with the avatar beside it, or above it when the
+// terminal is narrow or draws real pixels, which don't lay out like text.
+func (v aboutView) Styled(st *Styles, t *Catalog) string {
+	bio := v.Profile.Styled(st, t)
+	switch {
+	case v.avatar == "":
+		return bio
+	case v.beside:
+		return lipgloss.JoinHorizontal(lipgloss.Top, v.avatar, "  ", bio)
+	}
+	return v.avatar + "\n" + bio
+}
+
+// Styled renders the bio for the TUI.
+func (p Profile) Styled(st *Styles, t *Catalog) string {
 	var sb strings.Builder
 
-	sb.WriteString(Header("ABOUT ME"))
+	sb.WriteString(st.Header(strings.ToUpper(t.T("about.heading"))))
 	sb.WriteString("\n\n")
-	sb.WriteString(Label("Name: ") + Value("John Doe") + "\n")
-	sb.WriteString(Label("Role: ") + Value("Full Stack Developer & Tech Enthusiast") + "\n")
-	sb.WriteString(Label("Location: ") + Value("San Francisco, CA") + "\n\n")
-	sb.WriteString(ContentStyle.Render("Hello! I'm a passionate developer who loves building\n"))
-	sb.WriteString(ContentStyle.Render("innovative web applications and exploring cutting-edge\n"))
-	sb.WriteString(ContentStyle.Render("technologies. With expertise in both frontend and backend\n"))
-	sb.WriteString(ContentStyle.Render("development, I create seamless digital experiences that\n"))
-	sb.WriteString(ContentStyle.Render("make a difference.\n\n"))
-	sb.WriteString(ContentStyle.Foreground(PurpleColor).Render("When I'm not coding, you'll find me contributing to open\n"))
-	sb.WriteString(ContentStyle.Foreground(PurpleColor).Render("source projects, mentoring aspiring developers, or diving\n"))
-	sb.WriteString(ContentStyle.Foreground(PurpleColor).Render("into the latest tech trends.\n\n"))
-	sb.WriteString(Dim("Type 'skills' or 'experience' to learn more about my background."))
+	sb.WriteString(st.Label(t.T("field.name")+" ") + st.Value(p.Name) + "\n")
+	sb.WriteString(st.Label(t.T("field.role")+" ") + st.Value(p.Role) + "\n")
+	sb.WriteString(st.Label(t.T("field.location")+" ") + st.Value(p.Location) + "\n")
+	if p.Articles != "" {
+		sb.WriteString(st.Label(t.T("field.articles")+" ") + st.Link(st.Value(p.Articles), p.Articles) + "\n")
+	}
+	// Bio paragraphs are Markdown, wrapped inside the content padding
+	for _, para := range p.Bio {
+		sb.WriteString(st.Content.Render(RenderMarkdown(para, st, 56)) + "\n")
+	}
+	if p.Interests != "" {
+		interests := renderMarkdownIn(p.Interests, st, 56, st.Emphasis.UnsetPadding())
+		sb.WriteString(st.Emphasis.Render(interests) + "\n")
+	}
+	sb.WriteString("\n")
+	sb.WriteString(st.Dim(t.T("about.more")))
+
+	return sb.String()
+}
+
+// Plain renders the bio without styling.
+func (p Profile) Plain(t *Catalog) string {
+	var sb strings.Builder
+
+	sb.WriteString(strings.ToUpper(t.T("about.heading")) + "\n\n")
+	sb.WriteString(t.T("field.name") + " " + p.Name + "\n")
+	sb.WriteString(t.T("field.role") + " " + p.Role + "\n")
+	sb.WriteString(t.T("field.location") + " " + p.Location + "\n")
+	if p.Articles != "" {
+		sb.WriteString(t.T("field.articles") + " " + p.Articles + "\n")
+	}
+	for _, para := range p.Bio {
+		sb.WriteString("\n" + RenderMarkdown(para, nil, 0) + "\n")
+	}
+	if p.Interests != "" {
+		sb.WriteString("\n" + RenderMarkdown(p.Interests, nil, 0) + "\n")
+	}
 
 	return sb.String()
 }
 
-// skillsCommand displays technical skills
-func skillsCommand() string {
+// Markdown renders the bio as Markdown.
+func (p Profile) Markdown(t *Catalog) string {
 	var sb strings.Builder
 
-	sb.WriteString(Header("TECHNICAL SKILLS"))
+	sb.WriteString("# " + t.T("about.heading") + "\n\n")
+	sb.WriteString("- **" + t.T("field.name") + "** " + p.Name + "\n")
+	sb.WriteString("- **" + t.T("field.role") + "** " + p.Role + "\n")
+	sb.WriteString("- **" + t.T("field.location") + "** " + p.Location + "\n")
+	if p.Articles != "" {
+		sb.WriteString("- **" + t.T("field.articles") + "** [" + p.Articles + "](" + markdownURL(p.Articles) + ")\n")
+	}
+	for _, para := range p.Bio {
+		sb.WriteString("\n" + para + "\n")
+	}
+	if p.Interests != "" {
+		sb.WriteString("\n_" + p.Interests + "_\n")
+	}
+
+	return sb.String()
+}
+
+// skillsDocument returns technical skills
+func skillsDocument(ctx *CommandContext) (Document, error) {
+	c, err := LoadContentFor(ctx.Catalog.Locale)
+	if err != nil {
+		return nil, err
+	}
+	return c.Skills, nil
+}
+
+// Styled renders skills as an ASCII table.
+func (s Skills) Styled(st *Styles, t *Catalog) string {
+	var sb strings.Builder
+
+	sb.WriteString(st.Header(strings.ToUpper(t.T("skills.heading"))))
 	sb.WriteString("\n\n")
 
-	// ASCII table
-	table := []string{
-		TableBorderStyle.Render("┌────────────────────────┬──────────────────────────────────┐"),
-		TableBorderStyle.Render("│ ") + TableHeaderStyle.Render("Category              ") + TableBorderStyle.Render(" │ ") + TableHeaderStyle.Render("Technologies                    ") + TableBorderStyle.Render(" │"),
-		TableBorderStyle.Render("├────────────────────────┼──────────────────────────────────┤"),
-		TableBorderStyle.Render("│ ") + TableCellStyle.Render("Frontend              ") + TableBorderStyle.Render(" │ ") + "React, Vue.js, TypeScript       " + TableBorderStyle.Render("│"),
-		TableBorderStyle.Render("│                        │") + " Next.js, Astro, Tailwind CSS    " + TableBorderStyle.Render("│"),
-		TableBorderStyle.Render("├────────────────────────┼──────────────────────────────────┤"),
-		TableBorderStyle.Render("│ ") + TableCellStyle.Render("Backend               ") + TableBorderStyle.Render(" │ ") + "Node.js, Python, Go             " + TableBorderStyle.Render("│"),
-		TableBorderStyle.Render("│                        │") + " Express, FastAPI, PostgreSQL    " + TableBorderStyle.Render("│"),
-		TableBorderStyle.Render("├────────────────────────┼──────────────────────────────────┤"),
-		TableBorderStyle.Render("│ ") + TableCellStyle.Render("DevOps & Tools        ") + TableBorderStyle.Render(" │ ") + "Docker, Kubernetes, AWS         " + TableBorderStyle.Render("│"),
-		TableBorderStyle.Render("│                        │") + " Git, CI/CD, Terraform           " + TableBorderStyle.Render("│"),
-		TableBorderStyle.Render("├────────────────────────┼──────────────────────────────────┤"),
-		TableBorderStyle.Render("│ ") + TableCellStyle.Render("Databases             ") + TableBorderStyle.Render(" │ ") + "PostgreSQL, MongoDB, Redis      " + TableBorderStyle.Render("│"),
-		TableBorderStyle.Render("│                        │") + " GraphQL, REST APIs              " + TableBorderStyle.Render("│"),
-		TableBorderStyle.Render("├────────────────────────┼──────────────────────────────────┤"),
-		TableBorderStyle.Render("│ ") + TableCellStyle.Render("Other                 ") + TableBorderStyle.Render(" │ ") + "WebSockets, WebAssembly         " + TableBorderStyle.Render("│"),
-		TableBorderStyle.Render("│                        │") + " Testing, Agile, TDD             " + TableBorderStyle.Render("│"),
-		TableBorderStyle.Render("└────────────────────────┴──────────────────────────────────┘"),
-	}
-
-	for _, line := range table {
-		sb.WriteString(line + "\n")
+	const catWidth, techWidth = 22, 32
+	border := func(left, mid, right string) string {
+		return st.TableBorder.Render(left + strings.Repeat("─", catWidth+2) + mid + strings.Repeat("─", techWidth+2) + right)
+	}
+	bar := st.TableBorder.Render("│")
+	row := func(cat, tech string, catStyle func(...string) string) string {
+		return bar + " " + catStyle(padRight(cat, catWidth)) + " " + bar + " " + padRight(tech, techWidth) + " " + bar
 	}
 
+	sb.WriteString(border("┌", "┬", "┐") + "\n")
+	sb.WriteString(row(t.T("skills.category"), st.TableHeader.Render(padRight(t.T("skills.technologies"), techWidth)), st.TableHeader.Render) + "\n")
+	for _, cat := range s.Categories {
+		sb.WriteString(border("├", "┼", "┤") + "\n")
+		for i, line := range wrapList(cat.Technologies, techWidth) {
+			name := ""
+			if i == 0 {
+				name = cat.Name
+			}
+			sb.WriteString(row(name, line, st.TableCell.Render) + "\n")
+		}
+	}
+	sb.WriteString(border("└", "┴", "┘") + "\n")
+
 	sb.WriteString("\n")
-	sb.WriteString(ContentStyle.Foreground(PurpleColor).Render("★ Proficiency Level: Expert ████████░░ (80%)"))
+	sb.WriteString(st.Emphasis.Render(fmt.Sprintf("★ %s: %s %s (%d%%)",
+		t.T("skills.proficiency"), s.Proficiency.Level, proficiencyBar(s.Proficiency.Percent), s.Proficiency.Percent)))
 	sb.WriteString("\n")
 
 	return sb.String()
 }
 
-// experienceCommand displays work experience
-func experienceCommand() string {
+// Plain renders skills one category per line.
+func (s Skills) Plain(t *Catalog) string {
 	var sb strings.Builder
 
-	sb.WriteString(Header("WORK EXPERIENCE"))
-	sb.WriteString("\n\n")
+	sb.WriteString(strings.ToUpper(t.T("skills.heading")) + "\n\n")
+	for _, cat := range s.Categories {
+		sb.WriteString(cat.Name + ": " + strings.Join(cat.Technologies, ", ") + "\n")
+	}
+	sb.WriteString(fmt.Sprintf("\n%s: %s (%d%%)\n", t.T("skills.proficiency"), s.Proficiency.Level, s.Proficiency.Percent))
 
-	experiences := []struct {
-		role     string
-		company  string
-		period   string
-		desc     string
-	}{
-		{
-			role:    "Senior Full Stack Developer",
-			company: "Tech Innovators Inc.",
-			period:  "2021 - Present",
-			desc:    "Leading development of cloud-native applications, mentoring junior developers.",
-		},
-		{
-			role:    "Full Stack Developer",
-			company: "StartupXYZ",
-			period:  "2019 - 2021",
-			desc:    "Built scalable microservices architecture, implemented CI/CD pipelines.",
-		},
-		{
-			role:    "Junior Developer",
-			company: "WebDev Solutions",
-			period:  "2017 - 2019",
-			desc:    "Developed responsive web applications, collaborated on agile teams.",
-		},
+	return sb.String()
+}
+
+// Markdown renders skills as a Markdown table.
+func (s Skills) Markdown(t *Catalog) string {
+	var sb strings.Builder
+
+	sb.WriteString("# " + t.T("skills.heading") + "\n\n")
+	sb.WriteString("| " + t.T("skills.category") + " | " + t.T("skills.technologies") + " |\n")
+	sb.WriteString("| --- | --- |\n")
+	for _, cat := range s.Categories {
+		sb.WriteString("| " + cat.Name + " | " + strings.Join(cat.Technologies, ", ") + " |\n")
 	}
+	sb.WriteString(fmt.Sprintf("\n**%s:** %s (%d%%)\n", t.T("skills.proficiency"), s.Proficiency.Level, s.Proficiency.Percent))
 
-	for i, exp := range experiences {
-		sb.WriteString(Label(exp.role) + "\n")
-		sb.WriteString(Value(exp.company) + " | " + Dim(exp.period) + "\n")
-		sb.WriteString(ContentStyle.Render(exp.desc) + "\n")
-		if i < len(experiences)-1 {
-			sb.WriteString("\n")
+	return sb.String()
+}
+
+// proficiencyBar draws a ten-cell bar such as ████████░░ for percent.
+func proficiencyBar(percent int) string {
+	filled := max(0, min(10, percent/10))
+	return strings.Repeat("█", filled) + strings.Repeat("░", 10-filled)
+}
+
+// wrapList joins items with ", " into lines no wider than width.
+func wrapList(items []string, width int) []string {
+	var lines []string
+	line := ""
+	for _, item := range items {
+		switch {
+		case line == "":
+			line = item
+		case lipgloss.Width(line)+2+lipgloss.Width(item) <= width:
+			line += ", " + item
+		default:
+			lines = append(lines, line+",")
+			line = item
 		}
 	}
+	if line != "" {
+		lines = append(lines, line)
+	}
+	return lines
+}
 
-	return sb.String()
+// experienceDocument returns work experience
+func experienceDocument(ctx *CommandContext) (Document, error) {
+	c, err := LoadContentFor(ctx.Catalog.Locale)
+	if err != nil {
+		return nil, err
+	}
+	return c.Experience, nil
 }
 
-// linksCommand displays social links
-func linksCommand() string {
+// Styled renders the work history for the TUI.
+func (e ExperienceList) Styled(st *Styles, t *Catalog) string {
 	var sb strings.Builder
 
-	sb.WriteString(Header("SOCIAL LINKS"))
+	sb.WriteString(st.Header(strings.ToUpper(t.T("experience.heading"))))
 	sb.WriteString("\n\n")
 
-	links := []struct {
-		name string
-		url  string
-		icon string
-	}{
-		{"GitHub", "github.com/yourusername", "⚡"},
-		{"LinkedIn", "linkedin.com/in/yourprofile", "💼"},
-		{"Twitter", "twitter.com/yourhandle", "🐦"},
-		{"Email", "your.email@example.com", "📧"},
-		{"Website", "yourwebsite.com", "🌐"},
+	for i, exp := range e {
+		sb.WriteString(st.Label(exp.Role) + "\n")
+		company := st.Value(exp.Company)
+		if exp.URL != "" {
+			company = st.Link(company, exp.URL)
+		}
+		sb.WriteString(company + " | " + st.Dim(exp.Period) + "\n")
+		sb.WriteString(st.Content.Render(exp.Description) + "\n")
+		if i < len(e)-1 {
+			sb.WriteString("\n")
+		}
 	}
 
-	for _, link := range links {
-		sb.WriteString(fmt.Sprintf("%s %s %s\n",
-			link.icon,
-			Label(link.name+":"),
-			Value(link.url)))
-	}
+	return sb.String()
+}
 
-	sb.WriteString("\n")
-	sb.WriteString(Dim("Feel free to reach out!"))
+// Plain renders the work history without styling.
+func (e ExperienceList) Plain(t *Catalog) string {
+	var sb strings.Builder
+
+	sb.WriteString(strings.ToUpper(t.T("experience.heading")) + "\n")
+	for _, exp := range e {
+		sb.WriteString("\n" + exp.Role + "\n")
+		sb.WriteString(exp.Company + " | " + exp.Period + "\n")
+		if exp.URL != "" {
+			sb.WriteString(exp.URL + "\n")
+		}
+		sb.WriteString(exp.Description + "\n")
+	}
 
 	return sb.String()
 }
 
-// helpCommand displays all available commands
-func helpCommand() string {
+// Accessible renders each position as labeled lines.
+func (e ExperienceList) Accessible(t *Catalog) string {
 	var sb strings.Builder
 
-	sb.WriteString(Header("AVAILABLE COMMANDS"))
-	sb.WriteString("\n\n")
+	sb.WriteString(strings.ToUpper(t.T("experience.heading")) + ", " + t.T("experience.count", len(e)) + "\n")
+	for _, exp := range e {
+		sb.WriteString("\n" + t.T("field.role") + " " + exp.Role + "\n")
+		sb.WriteString(t.T("field.company") + " " + exp.Company + "\n")
+		if exp.URL != "" {
+			sb.WriteString(t.T("field.website") + " " + exp.URL + "\n")
+		}
+		sb.WriteString(t.T("field.period") + " " + exp.Period + "\n")
+		sb.WriteString(exp.Description + "\n")
+	}
 
-	commands := GetAllCommands()
+	return sb.String()
+}
 
-	// Group by category
-	categories := map[string][]Command{
-		"portfolio": {},
-		"system":    {},
+// Markdown renders the work history as Markdown.
+func (e ExperienceList) Markdown(t *Catalog) string {
+	var sb strings.Builder
+
+	sb.WriteString("# " + t.T("experience.heading") + "\n")
+	for _, exp := range e {
+		sb.WriteString("\n## " + exp.Role + "\n\n")
+		company := exp.Company
+		if exp.URL != "" {
+			company = "[" + company + "](" + markdownURL(exp.URL) + ")"
+		}
+		sb.WriteString("**" + company + "** | _" + exp.Period + "_\n\n")
+		sb.WriteString(exp.Description + "\n")
 	}
 
-	for _, cmd := range commands {
-		categories[cmd.Category] = append(categories[cmd.Category], cmd)
+	return sb.String()
+}
+
+// linksDocument returns social links
+func linksDocument(ctx *CommandContext) (Document, error) {
+	c, err := LoadContentFor(ctx.Catalog.Locale)
+	if err != nil {
+		return nil, err
 	}
+	return c.Links, nil
+}
+
+// Styled renders the links with their icons.
+func (l LinkList) Styled(st *Styles, t *Catalog) string {
+	var sb strings.Builder
 
-	// Portfolio commands
-	sb.WriteString(Label("Portfolio:") + "\n")
-	for _, cmd := range categories["portfolio"] {
-		sb.WriteString(fmt.Sprintf("  %s %s\n",
-			Value(padRight(cmd.Name, 12)),
-			cmd.Description))
+	sb.WriteString(st.Header(strings.ToUpper(t.T("links.heading"))))
+	sb.WriteString("\n\n")
+
+	for _, link := range l {
+		sb.WriteString(fmt.Sprintf("%s %s %s\n",
+			link.Icon,
+			st.Label(link.Name+":"),
+			st.Link(st.Value(link.URL), link.URL)))
 	}
+
 	sb.WriteString("\n")
+	sb.WriteString(st.Dim(t.T("links.outro")))
+
+	return sb.String()
+}
+
+// Plain renders one "name: url" pair per line.
+func (l LinkList) Plain(t *Catalog) string {
+	var sb strings.Builder
 
-	// System commands
-	sb.WriteString(Label("System:") + "\n")
-	for _, cmd := range categories["system"] {
-		sb.WriteString(fmt.Sprintf("  %s %s\n",
-			Value(padRight(cmd.Name, 12)),
-			cmd.Description))
+	sb.WriteString(strings.ToUpper(t.T("links.heading")) + "\n\n")
+	for _, link := range l {
+		sb.WriteString(link.Name + ": " + link.URL + "\n")
 	}
-	sb.WriteString("\n")
 
-	sb.WriteString(Dim("Navigation Tips:") + "\n")
-	sb.WriteString("  • Use ↑↓ or j/k to navigate menu\n")
-	sb.WriteString("  • Press Enter to select\n")
-	sb.WriteString("  • Press 'q' to quit\n")
+	return sb.String()
+}
+
+// Markdown renders the links as a Markdown list.
+func (l LinkList) Markdown(t *Catalog) string {
+	var sb strings.Builder
+
+	sb.WriteString("# " + t.T("links.heading") + "\n\n")
+	for _, link := range l {
+		sb.WriteString("- [" + link.Name + "](" + markdownURL(link.URL) + ")\n")
+	}
 
 	return sb.String()
 }
 
+// markdownURL turns the scheme-less URLs and addresses in content into
+// absolute link targets.
+func markdownURL(url string) string {
+	switch {
+	case strings.Contains(url, "://"):
+		return url
+	case strings.Contains(url, "@"):
+		return "mailto:" + url
+	}
+	return "https://" + url
+}
+
+// helpCommand displays all available commands
+func helpCommand(ctx *CommandContext) (string, error) {
+	st, t := ctx.Styles, ctx.Catalog
+	var sb strings.Builder
+
+	sb.WriteString(st.Header(strings.ToUpper(t.T("help.heading"))))
+	sb.WriteString("\n\n")
+
+	for _, section := range Sections(GetAllCommands()) {
+		sb.WriteString(st.Label(t.CategoryTitle(section.Category)+":") + "\n")
+		for _, cmd := range section.Commands {
+			sb.WriteString(fmt.Sprintf("  %s %s\n",
+				st.Value(padRight(cmd.Name, 12)),
+				t.Describe(cmd)))
+		}
+		sb.WriteString("\n")
+	}
+
+	bullet := "  • "
+	navigate, fold := t.T("help.navigate"), t.T("help.fold")
+	if st.Accessible {
+		bullet = "  "
+		navigate, fold = t.T("help.navigate_accessible"), t.T("help.fold_accessible")
+	}
+	sb.WriteString(st.Dim(t.T("help.tips")) + "\n")
+	sb.WriteString(bullet + navigate + "\n")
+	sb.WriteString(bullet + t.T("help.select") + "\n")
+	sb.WriteString(bullet + fold + "\n")
+	sb.WriteString(bullet + t.T("help.prompt") + "\n")
+	sb.WriteString(bullet + t.T("help.quit") + "\n")
+
+	return sb.String(), nil
+}
+
 // dateCommand displays current date and time
-func dateCommand() string {
+func dateCommand(ctx *CommandContext) (string, error) {
+	st, t := ctx.Styles, ctx.Catalog
 	now := time.Now()
 	return fmt.Sprintf("%s %s\n%s %s",
-		Label("Date:"),
-		Value(now.Format("Monday, January 2, 2006")),
-		Label("Time:"),
-		Value(now.Format("15:04:05 MST")))
+		st.Label(t.T("date.date")),
+		st.Value(longDate(t, now)),
+		st.Label(t.T("date.time")),
+		st.Value(now.Format("15:04:05 MST"))), nil
+}
+
+// longDate spells out the date of d in the catalog's language.
+func longDate(t *Catalog, d time.Time) string {
+	return t.T("date.long",
+		t.T(fmt.Sprintf("weekday.%d", d.Weekday())),
+		t.T(fmt.Sprintf("month.%d", d.Month())),
+		d.Day(), d.Year())
 }
 
 // whoamiCommand displays user info
-func whoamiCommand() string {
+func whoamiCommand(ctx *CommandContext) (string, error) {
+	st := ctx.Styles
+	user := ctx.User
+	if user == "" {
+		user = "guest"
+	}
 	return fmt.Sprintf("%s\n%s",
-		Label("SSH User:"),
-		Value("guest@genar.me"))
+		st.Label(ctx.Catalog.T("whoami.user")),
+		st.Value(user+"@genar.me")), nil
 }
 
 // Helper function to pad string to right
 func padRight(str string, length int) string {
-	if len(str) >= length {
-		return str
+	if w := lipgloss.Width(str); w < length {
+		return str + strings.Repeat(" ", length-w)
 	}
-	return str + strings.Repeat(" ", length-len(str))
+	return str
 }
@@ -0,0 +1,557 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"net/mail"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/charmbracelet/log"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// Contact message limits and delivery retries.
+const (
+	outboxFile          = "outbox.json"
+	contactMaxName      = 80
+	contactMaxEmail     = 254
+	contactMaxMessage   = 2000
+	contactMaxAttempts  = 10 // deliveries tried before a message is left for `inbox`
+	contactRetryPeriod  = 5 * time.Minute
+	contactSendTimeout  = 15 * time.Second
+	contactHourlyPerKey = 5   // messages a visitor may send per hour
+	contactKeep         = 500 // messages kept, the oldest handled ones are dropped
+)
+
+// ErrNoMessage is returned for a message ID that isn't in the outbox.
+var ErrNoMessage = errors.New("no such message")
+
+// ErrKept is returned by a Deliverer that leaves messages in the outbox
+// for `inbox` instead of passing them on, so they aren't marked delivered.
+var ErrKept = errors.New("kept in the outbox")
+
+// ErrOutboxFull is returned when every message kept is still waiting for
+// the admin, so none can be dropped to make room.
+var ErrOutboxFull = errors.New("outbox is full")
+
+// ContactMessage is a message a visitor sent with `contact`.
+type ContactMessage struct {
+	ID        int       `json:"id"`
+	Name      string    `json:"name"`
+	Email     string    `json:"email"`
+	Message   string    `json:"message"`
+	User      string    `json:"user"`             // SSH login name, "guest" on the web
+	Key       string    `json:"key,omitempty"`    // fingerprint of the visitor's SSH key
+	Remote    string    `json:"remote,omitempty"` // host of a visitor without a key
+	Time      time.Time `json:"time"`
+	Read      bool      `json:"read,omitempty"`
+	Delivered bool      `json:"delivered,omitempty"`
+	Attempts  int       `json:"attempts,omitempty"` // deliveries tried
+	Error     string    `json:"error,omitempty"`    // why the last delivery failed
+}
+
+// Outbox keeps contact messages in a JSON file in the data directory, so
+// none is lost while delivery is failing or the server restarts.
+type Outbox struct {
+	mu       sync.Mutex
+	file     string
+	messages []ContactMessage // oldest first
+	wake     chan struct{}    // signals the delivery loop
+	now      func() time.Time
+}
+
+// OpenOutbox loads the outbox stored in file.
+func OpenOutbox(file string) (*Outbox, error) {
+	o := &Outbox{file: file, wake: make(chan struct{}, 1), now: time.Now}
+	if err := loadJSON(file, &o.messages); err != nil {
+		return nil, err
+	}
+	return o, nil
+}
+
+var (
+	outboxOnce sync.Once
+	outbox     *Outbox
+	outboxErr  error
+)
+
+// LoadOutbox opens the server's outbox, shared by every session.
+func LoadOutbox() (*Outbox, error) {
+	outboxOnce.Do(func() {
+		outbox, outboxErr = OpenOutbox(outboxFile)
+	})
+	return outbox, outboxErr
+}
+
+// Add stores a new message and wakes the delivery loop. Past contactKeep
+// messages, the oldest ones the admin already has, read or delivered, are
+// dropped; when there are none, Add fails with ErrOutboxFull.
+func (o *Outbox) Add(m ContactMessage) (ContactMessage, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	m.ID = 1
+	if n := len(o.messages); n > 0 {
+		m.ID = o.messages[n-1].ID + 1
+	}
+	m.Time = o.now()
+	messages := append([]ContactMessage(nil), o.messages...)
+	for drop := len(messages) + 1 - contactKeep; drop > 0; drop-- {
+		i := 0
+		for i < len(messages) && !messages[i].Read && !messages[i].Delivered {
+			i++
+		}
+		if i == len(messages) {
+			return ContactMessage{}, ErrOutboxFull
+		}
+		messages = append(messages[:i], messages[i+1:]...)
+	}
+	messages = append(messages, m)
+	if err := saveJSON(o.file, messages); err != nil {
+		return ContactMessage{}, err
+	}
+	o.messages = messages
+	select {
+	case o.wake <- struct{}{}:
+	default:
+	}
+	return m, nil
+}
+
+// Sent counts the messages sent with key since the given time. Without a
+// key, it counts the keyless messages sent from remote.
+func (o *Outbox) Sent(key, remote string, since time.Time) int {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	n := 0
+	for _, m := range o.messages {
+		if m.Key == key && (key != "" || m.Remote == remote) && !m.Time.Before(since) {
+			n++
+		}
+	}
+	return n
+}
+
+// Messages returns every message, newest first.
+func (o *Outbox) Messages() []ContactMessage {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	list := make([]ContactMessage, 0, len(o.messages))
+	for i := len(o.messages) - 1; i >= 0; i-- {
+		list = append(list, o.messages[i])
+	}
+	return list
+}
+
+// MarkRead marks the message with the given ID read and returns it.
+func (o *Outbox) MarkRead(id int) (ContactMessage, error) {
+	return o.update(id, func(m *ContactMessage) { m.Read = true })
+}
+
+// update applies change to the message with the given ID and stores the
+// result.
+func (o *Outbox) update(id int, change func(m *ContactMessage)) (ContactMessage, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	for i, m := range o.messages {
+		if m.ID == id {
+			messages := append([]ContactMessage(nil), o.messages...)
+			change(&messages[i])
+			if err := saveJSON(o.file, messages); err != nil {
+				return ContactMessage{}, err
+			}
+			o.messages = messages
+			return messages[i], nil
+		}
+	}
+	return ContactMessage{}, ErrNoMessage
+}
+
+// pending returns the messages still to deliver.
+func (o *Outbox) pending() []ContactMessage {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	var list []ContactMessage
+	for _, m := range o.messages {
+		if !m.Delivered && m.Attempts < contactMaxAttempts {
+			list = append(list, m)
+		}
+	}
+	return list
+}
+
+// Deliver hands pending messages to d whenever one is added, and retries
+// the failed ones periodically, until ctx is done. Messages d keeps, with
+// ErrKept, are handed to it only once.
+func (o *Outbox) Deliver(ctx context.Context, d Deliverer, logger *log.Logger) {
+	ticker := time.NewTicker(contactRetryPeriod)
+	defer ticker.Stop()
+	kept := map[int]bool{}
+	for {
+		for _, m := range o.pending() {
+			if kept[m.ID] {
+				continue
+			}
+			sendCtx, cancel := context.WithTimeout(ctx, contactSendTimeout)
+			err := d.Deliver(sendCtx, m)
+			cancel()
+			if errors.Is(err, ErrKept) {
+				kept[m.ID] = true
+				continue
+			}
+			updated, uerr := o.update(m.ID, func(m *ContactMessage) {
+				m.Attempts++
+				m.Delivered = err == nil
+				m.Error = ""
+				if err != nil {
+					m.Error = err.Error()
+				}
+			})
+			switch {
+			case uerr != nil:
+				logger.Error("Failed to update the outbox", "message", m.ID, "error", uerr)
+			case err != nil:
+				logger.Warn("Failed to deliver contact message", "message", m.ID, "attempts", updated.Attempts, "error", err)
+			default:
+				logger.Info("Delivered contact message", "message", m.ID)
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-o.wake:
+		case <-ticker.C:
+		}
+	}
+}
+
+// Deliverer passes contact messages on to me.
+type Deliverer interface {
+	Deliver(ctx context.Context, m ContactMessage) error
+}
+
+// DelivererFunc adapts a function to Deliverer.
+type DelivererFunc func(ctx context.Context, m ContactMessage) error
+
+// Deliver calls f.
+func (f DelivererFunc) Deliver(ctx context.Context, m ContactMessage) error {
+	return f(ctx, m)
+}
+
+// ContactDelivererFromEnv picks how contact messages reach me:
+// CONTACT_WEBHOOK posts them as JSON, SMTP_ADDR mails them to CONTACT_TO,
+// and otherwise they are only logged, waiting in the outbox for `inbox`.
+func ContactDelivererFromEnv(logger *log.Logger) Deliverer {
+	if url := envOr("CONTACT_WEBHOOK", ""); url != "" {
+		return WebhookDeliverer(url)
+	}
+	if addr := envOr("SMTP_ADDR", ""); addr != "" {
+		return SMTPDeliverer{
+			Addr:     addr,
+			User:     envOr("SMTP_USER", ""),
+			Password: envOr("SMTP_PASSWORD", ""),
+			From:     envOr("CONTACT_FROM", "portfolio@genar.me"),
+			To:       envOr("CONTACT_TO", "hello@genar.me"),
+		}
+	}
+	return DelivererFunc(func(ctx context.Context, m ContactMessage) error {
+		logger.Info("Contact message waiting in the outbox", "message", m.ID)
+		return ErrKept
+	})
+}
+
+// WebhookDeliverer posts each message as JSON to a URL, such as a chat
+// webhook or a serverless function that forwards it.
+type WebhookDeliverer string
+
+// Deliver posts m.
+func (url WebhookDeliverer) Deliver(ctx context.Context, m ContactMessage) error {
+	body, err := json.Marshal(m)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, string(url), bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("webhook: %s", resp.Status)
+	}
+	return nil
+}
+
+// SMTPDeliverer mails each message, with the visitor's address to reply to.
+type SMTPDeliverer struct {
+	Addr           string // host:port of the mail server
+	User, Password string // for PLAIN authentication, if set
+	From, To       string
+}
+
+// Deliver mails m. The connection is bound to ctx, so a mail server that
+// stops answering can't hold it open past the send timeout.
+func (s SMTPDeliverer) Deliver(ctx context.Context, m ContactMessage) error {
+	host, _, _ := strings.Cut(s.Addr, ":")
+	// The name is a sanitized line, but headers mustn't get a line break
+	// from anywhere
+	name := strings.NewReplacer("\r", "", "\n", "").Replace(m.Name)
+	msg := "From: " + s.From + "\r\n" +
+		"To: " + s.To + "\r\n" +
+		"Reply-To: " + (&mail.Address{Name: name, Address: m.Email}).String() + "\r\n" +
+		"Subject: " + mime.QEncoding.Encode("utf-8", "Contact from "+name) + "\r\n" +
+		"Date: " + m.Time.Format(time.RFC1123Z) + "\r\n" +
+		"Content-Type: text/plain; charset=utf-8\r\n" +
+		"\r\n" + m.Message + "\r\n\r\n-- \r\nSent with `contact` by " + m.User + " " + m.Key + "\r\n"
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", s.Addr)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		if err := conn.SetDeadline(deadline); err != nil {
+			return err
+		}
+	}
+	c, err := smtp.NewClient(conn, host)
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+	if ok, _ := c.Extension("STARTTLS"); ok {
+		if err := c.StartTLS(&tls.Config{ServerName: host}); err != nil {
+			return err
+		}
+	}
+	if s.User != "" {
+		if err := c.Auth(smtp.PlainAuth("", s.User, s.Password, host)); err != nil {
+			return err
+		}
+	}
+	if err := c.Mail(s.From); err != nil {
+		return err
+	}
+	if err := c.Rcpt(s.To); err != nil {
+		return err
+	}
+	w, err := c.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, msg); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return c.Quit()
+}
+
+// contactFields are the fields of the contact form, in order.
+var contactFields = []string{"name", "email", "message"}
+
+// validateContact cleans the values of the contact form in place and
+// returns a message for each one that is wrong.
+func validateContact(t *Catalog, values map[string]string) map[string]string {
+	values["name"] = sanitizeLine(values["name"], contactMaxName)
+	values["email"] = sanitizeLine(values["email"], contactMaxEmail)
+	values["message"] = sanitizeText(values["message"], contactMaxMessage)
+
+	errs := map[string]string{}
+	if values["name"] == "" {
+		errs["name"] = t.T("contact.required")
+	}
+	switch addr, err := mail.ParseAddress(values["email"]); {
+	case values["email"] == "":
+		errs["email"] = t.T("contact.required")
+	case err != nil || addr.Address != values["email"] || !strings.Contains(addr.Address[strings.LastIndex(addr.Address, "@"):], "."):
+		errs["email"] = t.T("contact.invalid_email")
+	}
+	if n := len([]rune(values["message"])); n < 10 {
+		errs["message"] = t.T("contact.short_message", 10)
+	}
+	return errs
+}
+
+// contactCommand sends me a message. The TUI shows it as a form; exec
+// requests pass the fields as flags:
+//
+//	ssh genar.me contact --name Ada --email ada@example.com --message "Hi!"
+func contactCommand(ctx *CommandContext) (string, error) {
+	t := ctx.Catalog
+	fs := flag.NewFlagSet("contact", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	values := map[string]*string{}
+	for _, name := range contactFields {
+		values[name] = fs.String(name, "", name)
+	}
+	if err := fs.Parse(ctx.Args); err != nil || fs.NArg() > 0 {
+		return "", &UsageError{t.T("contact.usage")}
+	}
+
+	send := func(values map[string]string) (string, error) {
+		o, err := LoadOutbox()
+		if err != nil {
+			return "", err
+		}
+		// Visitors without a key are told apart by where they connect from,
+		// which for the web is the browser's address rather than the proxy's
+		var remote string
+		if ctx.Key == "" && ctx.Session != nil {
+			remote = ctx.Session.Host()
+		}
+		if o.Sent(ctx.Key, remote, o.now().Add(-time.Hour)) >= contactHourlyPerKey {
+			return "", errors.New(t.T("contact.rate_limited"))
+		}
+		_, err = o.Add(ContactMessage{
+			Name:    values["name"],
+			Email:   values["email"],
+			Message: values["message"],
+			User:    ctx.User,
+			Key:     ctx.Key,
+			Remote:  remote,
+		})
+		if errors.Is(err, ErrOutboxFull) {
+			return "", errors.New(t.T("contact.full"))
+		}
+		if err != nil {
+			return "", err
+		}
+		st := ctx.Styles
+		return st.Label(t.T("contact.sent", values["name"])) + "\n" + st.Dim(t.T("contact.reply", values["email"])), nil
+	}
+
+	// Flags send at once, without a form
+	if fs.NFlag() > 0 {
+		given := map[string]string{}
+		for name, v := range values {
+			given[name] = *v
+		}
+		if errs := validateContact(t, given); len(errs) > 0 {
+			var lines []string
+			for _, name := range contactFields {
+				if msg := errs[name]; msg != "" {
+					lines = append(lines, t.T("contact.field."+name)+" "+msg)
+				}
+			}
+			return "", &UsageError{"contact: " + strings.Join(lines, "; ")}
+		}
+		return send(given)
+	}
+
+	user := ctx.User
+	if user == "guest" {
+		user = ""
+	}
+	ctx.Form = &Form{
+		Title:  t.T("contact.heading"),
+		Submit: t.T("contact.submit"),
+		Fields: []FormField{
+			{Name: "name", Label: t.T("contact.field.name"), Value: user, Max: contactMaxName, Lines: 1},
+			{Name: "email", Label: t.T("contact.field.email"), Max: contactMaxEmail, Lines: 1},
+			{Name: "message", Label: t.T("contact.field.message"), Max: contactMaxMessage, Lines: 5, Multiline: true},
+		},
+		Validate: func(values map[string]string) map[string]string { return validateContact(t, values) },
+		Send:     send,
+	}
+	// Shown outside the TUI, where there is no form
+	return t.T("contact.usage"), nil
+}
+
+// inboxCommand lets admins read the contact messages and mark them read.
+func inboxCommand(ctx *CommandContext) (string, error) {
+	st, t := ctx.Styles, ctx.Catalog
+	if !IsAdmin(ctx.Key) {
+		return "", errors.New(t.T("admin.denied"))
+	}
+	o, err := LoadOutbox()
+	if err != nil {
+		return "", err
+	}
+
+	if len(ctx.Args) == 2 && ctx.Args[0] == "read" {
+		id, err := strconv.Atoi(strings.TrimPrefix(ctx.Args[1], "#"))
+		if err != nil {
+			return "", &UsageError{t.T("inbox.usage")}
+		}
+		m, err := o.MarkRead(id)
+		if errors.Is(err, ErrNoMessage) {
+			return "", errors.New(t.T("inbox.unknown", id))
+		}
+		if err != nil {
+			return "", err
+		}
+		return st.Label(t.T("inbox.marked", id)) + "\n\n" + renderContactMessage(st, t, m, true), nil
+	}
+	if len(ctx.Args) > 0 {
+		return "", &UsageError{t.T("inbox.usage")}
+	}
+
+	var sb strings.Builder
+	messages := o.Messages()
+	unread := 0
+	for _, m := range messages {
+		if !m.Read {
+			unread++
+		}
+	}
+	sb.WriteString(st.Header(strings.ToUpper(t.T("inbox.heading"))) + "\n\n")
+	sb.WriteString(st.Dim(t.T("inbox.count", len(messages), unread)) + "\n\n")
+	for _, m := range messages {
+		sb.WriteString(renderContactMessage(st, t, m, false) + "\n\n")
+	}
+	sb.WriteString(st.Dim(t.T("inbox.usage")))
+	return sb.String(), nil
+}
+
+// renderContactMessage renders a message for `inbox`, cut to a few lines
+// unless full is set.
+func renderContactMessage(st *Styles, t *Catalog, m ContactMessage, full bool) string {
+	status := t.T("inbox.unread")
+	if m.Read {
+		status = t.T("inbox.read")
+	}
+	delivery := t.T("inbox.delivered")
+	switch {
+	case m.Delivered:
+	case m.Error != "":
+		delivery = t.T("inbox.failed", m.Attempts, m.Error)
+	default:
+		delivery = t.T("inbox.pending")
+	}
+
+	head := st.Label("#"+strconv.Itoa(m.ID)) + " " + st.Value(m.Name+" <"+m.Email+">") + " " + st.Dim(m.Time.Format("2006-01-02 15:04"))
+	if !m.Read {
+		head += " " + st.Prompt.Render(status)
+	} else {
+		head += " " + st.Dim(status)
+	}
+	text := ansi.Wordwrap(m.Message, 72, "")
+	if lines := strings.Split(text, "\n"); !full && len(lines) > 3 {
+		text = strings.Join(lines[:3], "\n") + " …"
+	}
+	from := m.User
+	if m.Key != "" {
+		from += " " + shortFingerprint(m.Key)
+	}
+	return head + "\n" + st.Dim(from+" · "+delivery) + "\n" + text
+}
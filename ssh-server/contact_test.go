@@ -0,0 +1,235 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/charmbracelet/log"
+)
+
+func TestValidateContact(t *testing.T) {
+	cat := CatalogFor(LocaleEnglish)
+	tests := []struct {
+		email string
+		ok    bool
+	}{
+		{"ada@example.com", true},
+		{"ada", false},
+		{"ada@localhost", false},
+		{"Ada <ada@example.com>", false},
+		{"ada@example.com\r\nBcc: eve@example.com", false},
+	}
+	for _, tt := range tests {
+		values := map[string]string{"name": "Ada", "email": tt.email, "message": "Hello, nice site!"}
+		errs := validateContact(cat, values)
+		if ok := len(errs) == 0; ok != tt.ok {
+			t.Errorf("email %q: errors %v, want ok = %v", tt.email, errs, tt.ok)
+		}
+	}
+
+	values := map[string]string{"name": " \x1b[31m ", "email": "", "message": "hi"}
+	if errs := validateContact(cat, values); len(errs) != 3 {
+		t.Errorf("empty fields: errors %v, want one per field", errs)
+	}
+
+	// Messages keep their line breaks, and nothing else invisible
+	values = map[string]string{"name": "Ada", "email": "ada@example.com", "message": "Hi,  \r\n\r\n\n\x1b[2Jnice\tsite!\x07\n\n"}
+	if errs := validateContact(cat, values); len(errs) > 0 || values["message"] != "Hi,\n\nnice site!" {
+		t.Errorf("multi-line message: %q, errors %v; want its lines cleaned", values["message"], errs)
+	}
+}
+
+func TestOutboxDeliver(t *testing.T) {
+	dataDir = t.TempDir()
+	o, err := OpenOutbox(outboxFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := o.Add(ContactMessage{Name: "Ada", Email: "ada@example.com", Message: "Hello"}); err != nil {
+		t.Fatal(err)
+	}
+
+	// A failed delivery is kept for the next try, then the loop stops
+	// after the one that succeeds.
+	ctx, cancel := context.WithCancel(context.Background())
+	tries := 0
+	d := DelivererFunc(func(_ context.Context, m ContactMessage) error {
+		tries++
+		if tries == 1 {
+			return errors.New("unreachable") // Add left a wake-up for the retry
+		}
+		cancel()
+		return nil
+	})
+	o.Deliver(ctx, d, log.New(io.Discard))
+
+	o, err = OpenOutbox(outboxFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := o.Messages()[0]
+	if !m.Delivered || m.Attempts != 2 || m.Error != "" {
+		t.Errorf("after a failure and a retry: %+v, want delivered in 2 attempts", m)
+	}
+	if _, err := o.MarkRead(9); !errors.Is(err, ErrNoMessage) {
+		t.Errorf("marking a missing message: err = %v, want ErrNoMessage", err)
+	}
+}
+
+func TestOutboxLimits(t *testing.T) {
+	dataDir = t.TempDir()
+	o, err := OpenOutbox(outboxFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Keyless messages are counted per address, apart from keyed ones
+	for _, m := range []ContactMessage{{Remote: "192.0.2.1"}, {Remote: "192.0.2.1"}, {Remote: "192.0.2.2"}, {Key: "SHA256:ada"}} {
+		if _, err := o.Add(m); err != nil {
+			t.Fatal(err)
+		}
+	}
+	hour := o.now().Add(-time.Hour)
+	if n := o.Sent("", "192.0.2.1", hour); n != 2 {
+		t.Errorf("sent from 192.0.2.1 = %d, want 2", n)
+	}
+	if n := o.Sent("SHA256:ada", "", hour); n != 1 {
+		t.Errorf("sent with a key = %d, want 1", n)
+	}
+
+	// A full outbox drops the oldest handled message, and refuses new ones
+	// once every message is still waiting
+	for len(o.Messages()) < contactKeep {
+		if _, err := o.Add(ContactMessage{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := o.MarkRead(2); err != nil {
+		t.Fatal(err)
+	}
+	m, err := o.Add(ContactMessage{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := len(o.Messages()); n != contactKeep || m.ID != contactKeep+1 {
+		t.Errorf("after adding to a full outbox: %d messages, ID %d, want %d and %d", n, m.ID, contactKeep, contactKeep+1)
+	}
+	if _, err := o.MarkRead(2); !errors.Is(err, ErrNoMessage) {
+		t.Errorf("the read message was kept: err = %v", err)
+	}
+	if _, err := o.Add(ContactMessage{}); !errors.Is(err, ErrOutboxFull) {
+		t.Errorf("adding with nothing to drop: err = %v, want ErrOutboxFull", err)
+	}
+}
+
+func TestOutboxWithoutDelivery(t *testing.T) {
+	dataDir = t.TempDir()
+	t.Setenv("CONTACT_WEBHOOK", "")
+	t.Setenv("SMTP_ADDR", "")
+	o, err := OpenOutbox(outboxFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < contactKeep; i++ {
+		if _, err := o.Add(ContactMessage{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Messages that are only logged stay waiting for the admin, each one
+	// logged once, so a full outbox has none to drop
+	ctx, cancel := context.WithCancel(context.Background())
+	d, logged := ContactDelivererFromEnv(log.New(io.Discard)), 0
+	o.Deliver(ctx, DelivererFunc(func(ctx context.Context, m ContactMessage) error {
+		if logged++; logged == contactKeep {
+			cancel()
+		}
+		return d.Deliver(ctx, m)
+	}), log.New(io.Discard))
+
+	if logged != contactKeep {
+		t.Errorf("logged %d times, want once per message", logged)
+	}
+	for _, m := range o.Messages() {
+		if m.Delivered || m.Attempts > 0 {
+			t.Fatalf("message %d was only logged: %+v, want it waiting", m.ID, m)
+		}
+	}
+	if _, err := o.Add(ContactMessage{}); !errors.Is(err, ErrOutboxFull) {
+		t.Errorf("adding to a full outbox of logged messages: err = %v, want ErrOutboxFull", err)
+	}
+}
+
+func TestSMTPDeliverer(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+
+	// A mail server that takes one message, then one that never answers
+	data := make(chan string, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		r := bufio.NewReader(conn)
+		fmt.Fprint(conn, "220 test\r\n")
+		var body strings.Builder
+		for inData := false; ; {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				return
+			}
+			switch {
+			case inData && line == ".\r\n":
+				inData = false
+				data <- body.String()
+				fmt.Fprint(conn, "250 ok\r\n")
+			case inData:
+				body.WriteString(line)
+			case strings.HasPrefix(line, "DATA"):
+				inData = true
+				fmt.Fprint(conn, "354 go on\r\n")
+			case strings.HasPrefix(line, "QUIT"):
+				fmt.Fprint(conn, "221 bye\r\n")
+				return
+			default:
+				fmt.Fprint(conn, "250 ok\r\n")
+			}
+		}
+	}()
+
+	d := SMTPDeliverer{Addr: ln.Addr().String(), From: "site@example.com", To: "me@example.com"}
+	m := ContactMessage{Name: "Núria\r\nBcc: eve@example.com", Email: "nuria@example.com", Message: "Hello"}
+	if err := d.Deliver(context.Background(), m); err != nil {
+		t.Fatal(err)
+	}
+	msg := <-data
+	if !strings.Contains(msg, "Subject: =?utf-8?q?Contact_from_N=C3=BAriaBcc:_eve@example.com?=\r\n") || strings.Contains(msg, "\r\nBcc:") {
+		t.Errorf("message headers:\n%s\nwant the name encoded on the subject line", msg)
+	}
+
+	go func() {
+		conn, err := ln.Accept()
+		if err == nil {
+			defer conn.Close()
+			time.Sleep(time.Second)
+		}
+	}()
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if err := d.Deliver(ctx, m); err == nil || time.Since(start) > 500*time.Millisecond {
+		t.Errorf("delivering to a silent server: err = %v after %s, want a timeout", err, time.Since(start))
+	}
+}
@@ -0,0 +1,194 @@
+package main
+
+import (
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"strings"
+	"sync"
+)
+
+// contentFS holds the portfolio data shared by the TUI, exec mode and the
+// HTTP API. Edit the JSON files in content/ to change what visitors see, and
+// the ones in content/<locale>/ for translations.
+//
+//go:embed content
+var contentFS embed.FS
+
+// Profile is the personal information shown by `about`.
+type Profile struct {
+	Name      string   `json:"name" yaml:"name"`
+	Role      string   `json:"role" yaml:"role"`
+	Location  string   `json:"location" yaml:"location"`
+	Bio       []string `json:"bio" yaml:"bio"`
+	Interests string   `json:"interests" yaml:"interests"`
+	Articles  string   `json:"articles,omitempty" yaml:"articles,omitempty"` // where I write, linked from `about`
+}
+
+// SkillCategory groups related technologies.
+type SkillCategory struct {
+	Name         string   `json:"name" yaml:"name"`
+	Technologies []string `json:"technologies" yaml:"technologies"`
+}
+
+// Proficiency is the overall self-assessed skill level.
+type Proficiency struct {
+	Level   string `json:"level" yaml:"level"`
+	Percent int    `json:"percent" yaml:"percent"`
+}
+
+// Skills is the data behind `skills`.
+type Skills struct {
+	Categories  []SkillCategory `json:"categories" yaml:"categories"`
+	Proficiency Proficiency     `json:"proficiency" yaml:"proficiency"`
+}
+
+// Experience is a single position in the work history.
+type Experience struct {
+	Role        string `json:"role" yaml:"role"`
+	Company     string `json:"company" yaml:"company"`
+	Period      string `json:"period" yaml:"period"`
+	Description string `json:"description" yaml:"description"`
+	URL         string `json:"url,omitempty" yaml:"url,omitempty"` // company website
+}
+
+// ExperienceList is the data behind `experience`, most recent first.
+type ExperienceList []Experience
+
+// Link is a social or contact link.
+type Link struct {
+	ID   string `json:"id" yaml:"id"`
+	Name string `json:"name" yaml:"name"`
+	URL  string `json:"url" yaml:"url"`
+	Icon string `json:"icon,omitempty" yaml:"icon,omitempty"`
+}
+
+// LinkList is the data behind `links`.
+type LinkList []Link
+
+// Project is something I built, shown by `projects` and as a file in
+// ~/projects.
+type Project struct {
+	ID          string   `json:"id" yaml:"id"` // file name and command argument; the same in every language
+	Name        string   `json:"name" yaml:"name"`
+	Year        string   `json:"year" yaml:"year"`
+	Status      string   `json:"status" yaml:"status"` // active, maintained or archived
+	Description string   `json:"description" yaml:"description"`
+	Tags        []string `json:"tags" yaml:"tags"` // the tech stack, used to filter with `projects --tag`
+	URL         string   `json:"url,omitempty" yaml:"url,omitempty"`
+	Repo        string   `json:"repo,omitempty" yaml:"repo,omitempty"`
+	Body        string   `json:"body,omitempty" yaml:"body,omitempty"` // README-like write-up in Markdown, from content/projects/<id>.md
+}
+
+// HasTag reports whether the project is tagged tag, ignoring case.
+func (p Project) HasTag(tag string) bool {
+	for _, t := range p.Tags {
+		if strings.EqualFold(t, tag) {
+			return true
+		}
+	}
+	return false
+}
+
+// ProjectList is the list of projects, most recent first.
+type ProjectList []Project
+
+// Content is the complete portfolio.
+type Content struct {
+	Profile    Profile
+	Skills     Skills
+	Experience ExperienceList
+	Links      LinkList
+	Projects   ProjectList
+}
+
+var (
+	contentMu     sync.Mutex
+	loadedContent = map[Locale]*Content{}
+)
+
+// LoadContent parses the default locale's content.
+func LoadContent() (*Content, error) {
+	return LoadContentFor(DefaultLocale)
+}
+
+// LoadContentFor parses the embedded content files for locale l. A file in
+// content/<locale>/ replaces the default one of the same name, so
+// translations can cover only the files that have prose in them. Results are
+// cached since embedded files cannot change while the server runs.
+func LoadContentFor(l Locale) (*Content, error) {
+	contentMu.Lock()
+	defer contentMu.Unlock()
+	if c, ok := loadedContent[l]; ok {
+		return c, nil
+	}
+
+	c := &Content{}
+	files := []struct {
+		name string
+		dst  interface{}
+	}{
+		{"profile.json", &c.Profile},
+		{"skills.json", &c.Skills},
+		{"experience.json", &c.Experience},
+		{"links.json", &c.Links},
+		{"projects.json", &c.Projects},
+	}
+	for _, f := range files {
+		name, data, err := readContentFile(l, f.name)
+		if err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(data, f.dst); err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+	}
+
+	// Write-ups are optional, one Markdown file per project
+	for i, p := range c.Projects {
+		_, data, err := readContentFile(l, "projects/"+p.ID+".md")
+		switch {
+		case errors.Is(err, fs.ErrNotExist):
+		case err != nil:
+			return nil, err
+		default:
+			c.Projects[i].Body = string(data)
+		}
+	}
+	loadedContent[l] = c
+	return c, nil
+}
+
+// readContentFile reads the named file from content/<locale>/, or from
+// content/ when the locale doesn't have its own.
+func readContentFile(l Locale, file string) (string, []byte, error) {
+	name := "content/" + string(l) + "/" + file
+	data, err := contentFS.ReadFile(name)
+	if errors.Is(err, fs.ErrNotExist) {
+		name = "content/" + file
+		data, err = contentFS.ReadFile(name)
+	}
+	return name, data, err
+}
+
+var (
+	avatarOnce sync.Once
+	avatar     *Image
+	avatarErr  error
+)
+
+// LoadAvatar decodes content/avatar.png, the picture shown by `about`. It
+// is the same image the website uses (public/genar-avatar.png).
+func LoadAvatar() (*Image, error) {
+	avatarOnce.Do(func() {
+		data, err := contentFS.ReadFile("content/avatar.png")
+		if err != nil {
+			avatarErr = err
+			return
+		}
+		avatar, avatarErr = LoadImage(data)
+	})
+	return avatar, avatarErr
+}
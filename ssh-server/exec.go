@@ -0,0 +1,119 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/charmbracelet/log"
+	"github.com/charmbracelet/ssh"
+	"github.com/charmbracelet/wish"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// Exit statuses for exec requests, following shell conventions.
+const (
+	exitOK       = 0
+	exitError    = 1
+	exitUsage    = 2
+	exitNotFound = 127
+)
+
+// execMiddleware serves SSH exec requests (`ssh genar.me about`) by running
+// the matching command, printing its output and exiting with a status code
+// instead of starting the TUI. Sessions without a command fall through to the
+// TUI, or get a usage message when no terminal was requested.
+func execMiddleware(logger *log.Logger) wish.Middleware {
+	return func(next ssh.Handler) ssh.Handler {
+		return func(s ssh.Session) {
+			args := s.Command()
+			pty, _, hasPty := s.Pty()
+
+			if len(args) == 0 {
+				if hasPty {
+					next(s)
+					return
+				}
+				fmt.Fprint(s.Stderr(), "This server needs an interactive terminal (ssh -t) or a command.\n\n"+usage())
+				_ = s.Exit(exitUsage)
+				return
+			}
+
+			ctx := &CommandContext{
+				Args:     args[1:],
+				User:     s.User(),
+				Key:      SSHKeyFingerprint(s),
+				Session:  sessionFromContext(s),
+				Sessions: sessionFromContext(s).Registry(),
+				Format:   FormatPlain,
+				Styles:   NewStyles(MustTheme(DefaultTheme), NewSessionRenderer(s, SSHColorProfile(s))).WithHyperlinks(SSHHyperlinks(s)),
+				Catalog:  CatalogFor(SSHLocale(s)),
+			}
+			if hasPty {
+				ctx.Format = FormatStyled
+				ctx.Clipboard = OSC52Clipboard(s, pty.Term)
+				ctx.Width = pty.Window.Width
+				ctx.Images = SSHImageProtocol(s)
+			}
+			if SSHAccessible(s) {
+				ctx.SetAccessible(true)
+			}
+			code := runExec(s, s.Stderr(), args, ctx, hasPty)
+			logger.Info("exec", "session", SessionID(s), "command", args[0], "status", code)
+			_ = s.Exit(code)
+		}
+	}
+}
+
+// runExec runs the command named by args[0] and writes its output to stdout,
+// or an error and usage message to stderr. Output is styled only when the
+// client allocated a terminal, so pipes and redirects get plain text. Bad
+// arguments exit with exitUsage, so scripts can tell them from failures.
+func runExec(stdout, stderr io.Writer, args []string, ctx *CommandContext, styled bool) int {
+	cmd, ok := FindCommand(args[0])
+	if !ok {
+		fmt.Fprintf(stderr, "unknown command %q\n\n%s", args[0], usage())
+		return exitNotFound
+	}
+
+	output, err := cmd.Run(ctx)
+	var usageErr *UsageError
+	switch {
+	case errors.As(err, &usageErr):
+		fmt.Fprintf(stderr, "%s\n", err)
+		return exitUsage
+	case err != nil:
+		fmt.Fprintf(stderr, "%s\n", err)
+		return exitError
+	case ctx.Form != nil:
+		// The command wanted a form, which needs the TUI; its output says
+		// how to pass the values instead
+		fmt.Fprintf(stderr, "%s\n", ansi.Strip(output))
+		return exitUsage
+	}
+
+	if !styled {
+		output = ansi.Strip(output)
+	}
+	if !strings.HasSuffix(output, "\n") {
+		output += "\n"
+	}
+	if _, err := io.WriteString(stdout, output); err != nil {
+		return exitError
+	}
+	return exitOK
+}
+
+// usage lists the commands available to exec requests as plain text.
+func usage() string {
+	var sb strings.Builder
+	sb.WriteString("usage: ssh genar.me [command] [args...]\n")
+	for _, section := range Sections(GetAllCommands()) {
+		sb.WriteString("\n" + section.Category.Title + " commands:\n")
+		for _, cmd := range section.Commands {
+			sb.WriteString(fmt.Sprintf("  %s %s\n", padRight(cmd.Name, 12), cmd.Description))
+		}
+	}
+	return sb.String()
+}
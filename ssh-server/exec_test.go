@@ -0,0 +1,28 @@
+package main
+
+import (
+	"io"
+	"testing"
+)
+
+func TestRunExecStatus(t *testing.T) {
+	tests := []struct {
+		args []string
+		want int
+	}{
+		{[]string{"about"}, exitOK},
+		{[]string{"nope"}, exitNotFound},
+		{[]string{"about", "--bogus"}, exitUsage},
+		{[]string{"about", "extra"}, exitUsage},
+		{[]string{"about", "--format", "xml"}, exitUsage},
+		{[]string{"theme", "nope"}, exitUsage},
+		{[]string{"contact"}, exitUsage},
+		{[]string{"contact", "--email", "ada"}, exitUsage},
+	}
+	for _, tt := range tests {
+		ctx := &CommandContext{Args: tt.args[1:], User: "guest", Format: FormatPlain}
+		if got := runExec(io.Discard, io.Discard, tt.args, ctx, false); got != tt.want {
+			t.Errorf("runExec(%q) = %d, want %d", tt.args, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,479 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"html"
+	"os"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Article is a post from my blog's feed.
+type Article struct {
+	Title   string    `json:"title" yaml:"title"`
+	URL     string    `json:"url" yaml:"url"`
+	Date    time.Time `json:"date" yaml:"date"`
+	Summary string    `json:"summary,omitempty" yaml:"summary,omitempty"`
+	Body    string    `json:"body,omitempty" yaml:"body,omitempty"`
+}
+
+// Slug returns the last part of the article's URL, used as its file name.
+func (a Article) Slug() string {
+	name := a.URL[strings.LastIndex(strings.TrimRight(a.URL, "/"), "/")+1:]
+	if slug := slugify(strings.TrimSuffix(name, path.Ext(name))); slug != "" {
+		return slug
+	}
+	return slugify(a.Title)
+}
+
+// ArticleList is the feed's articles, newest first.
+type ArticleList []Article
+
+// jsonFeed is the part of a JSON Feed (jsonfeed.org) that we read.
+type jsonFeed struct {
+	Items []struct {
+		URL           string    `json:"url"`
+		Title         string    `json:"title"`
+		DatePublished time.Time `json:"date_published"`
+		Summary       string    `json:"summary"`
+		ContentText   string    `json:"content_text"`
+		ContentHTML   string    `json:"content_html"`
+	} `json:"items"`
+}
+
+// rssFeed is the part of an RSS 2.0 feed that we read.
+type rssFeed struct {
+	Items []struct {
+		Title       string `xml:"title"`
+		Link        string `xml:"link"`
+		PubDate     string `xml:"pubDate"`
+		Description string `xml:"description"`
+		Content     string `xml:"http://purl.org/rss/1.0/modules/content/ encoded"`
+	} `xml:"channel>item"`
+}
+
+// atomFeed is the part of an Atom feed that we read.
+type atomFeed struct {
+	Entries []struct {
+		Title string `xml:"title"`
+		Links []struct {
+			Href string `xml:"href,attr"`
+			Rel  string `xml:"rel,attr"`
+		} `xml:"link"`
+		Published string   `xml:"published"`
+		Updated   string   `xml:"updated"`
+		Summary   atomText `xml:"summary"`
+		Content   atomText `xml:"content"`
+	} `xml:"entry"`
+}
+
+// atomText is an Atom text construct, whose type says how to read it.
+type atomText struct {
+	Type  string `xml:"type,attr"`
+	Text  string `xml:",chardata"`
+	Inner string `xml:",innerxml"`
+}
+
+// markdown returns the text as Markdown.
+func (a atomText) markdown() string {
+	switch a.Type {
+	case "html":
+		return htmlToMarkdown(a.Text)
+	case "xhtml":
+		return htmlToMarkdown(a.Inner)
+	}
+	return strings.TrimSpace(a.Text)
+}
+
+// ParseFeed reads the articles of a JSON Feed, RSS 2.0 or Atom document,
+// telling them apart by their first element. HTML summaries and bodies are
+// turned into Markdown. Articles come back newest first.
+func ParseFeed(data []byte) (ArticleList, error) {
+	data = bytes.TrimPrefix(bytes.TrimSpace(data), []byte("\ufeff"))
+	var list ArticleList
+	if bytes.HasPrefix(data, []byte("{")) {
+		var feed jsonFeed
+		if err := json.Unmarshal(data, &feed); err != nil {
+			return nil, err
+		}
+		for _, item := range feed.Items {
+			body := item.ContentText
+			if body == "" {
+				body = htmlToMarkdown(item.ContentHTML)
+			}
+			list = append(list, Article{
+				Title:   item.Title,
+				URL:     item.URL,
+				Date:    item.DatePublished,
+				Summary: item.Summary,
+				Body:    body,
+			})
+		}
+		return list.sorted(), nil
+	}
+
+	root, err := xmlRoot(data)
+	if err != nil {
+		return nil, err
+	}
+	switch root {
+	case "rss":
+		var feed rssFeed
+		if err := xml.Unmarshal(data, &feed); err != nil {
+			return nil, err
+		}
+		for _, item := range feed.Items {
+			list = append(list, Article{
+				Title:   strings.TrimSpace(item.Title),
+				URL:     strings.TrimSpace(item.Link),
+				Date:    parseFeedDate(item.PubDate),
+				Summary: htmlToMarkdown(item.Description),
+				Body:    htmlToMarkdown(item.Content),
+			})
+		}
+	case "feed":
+		var feed atomFeed
+		if err := xml.Unmarshal(data, &feed); err != nil {
+			return nil, err
+		}
+		for _, entry := range feed.Entries {
+			a := Article{
+				Title:   strings.TrimSpace(entry.Title),
+				Date:    parseFeedDate(entry.Published),
+				Summary: entry.Summary.markdown(),
+				Body:    entry.Content.markdown(),
+			}
+			if a.Date.IsZero() {
+				a.Date = parseFeedDate(entry.Updated)
+			}
+			for _, link := range entry.Links {
+				if link.Rel == "" || link.Rel == "alternate" {
+					a.URL = link.Href
+					break
+				}
+			}
+			list = append(list, a)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported feed format <%s>", root)
+	}
+	return list.sorted(), nil
+}
+
+// xmlRoot returns the local name of the document's first element.
+func xmlRoot(data []byte) (string, error) {
+	d := xml.NewDecoder(bytes.NewReader(data))
+	for {
+		tok, err := d.Token()
+		if err != nil {
+			return "", err
+		}
+		if el, ok := tok.(xml.StartElement); ok {
+			return el.Name.Local, nil
+		}
+	}
+}
+
+// feedDateLayouts are the date formats found in feeds: RFC 822 and its
+// variants for RSS, RFC 3339 for Atom.
+var feedDateLayouts = []string{
+	time.RFC1123Z,
+	time.RFC1123,
+	"Mon, 2 Jan 2006 15:04:05 -0700",
+	"Mon, 2 Jan 2006 15:04:05 MST",
+	"2 Jan 2006 15:04:05 -0700",
+	time.RFC822Z,
+	time.RFC822,
+	time.RFC3339,
+	"2006-01-02",
+}
+
+// parseFeedDate reads a feed date, or returns the zero time.
+func parseFeedDate(s string) time.Time {
+	s = strings.TrimSpace(s)
+	for _, layout := range feedDateLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+// sorted orders the articles newest first.
+func (l ArticleList) sorted() ArticleList {
+	sort.SliceStable(l, func(i, j int) bool { return l[i].Date.After(l[j].Date) })
+	return l
+}
+
+// feedFile is the feed LoadArticles reads, kept up to date by a separate
+// job so requests never wait on the network. Without it the copy embedded
+// from content/feed.json is used.
+var feedFile = os.Getenv("FEED_FILE")
+
+var (
+	articlesMu  sync.Mutex
+	articles    ArticleList
+	articlesErr error
+	articlesGen int       // bumped whenever articles change
+	feedModTime time.Time // of the feed file last read
+	feedSize    int64
+)
+
+// LoadArticles returns the articles of my blog's feed. The feed file is
+// read again whenever it changes; if the new copy can't be read, say while
+// the refresh job is writing it, the articles read before stay. Articles
+// are not translated, so every language gets the same ones.
+func LoadArticles() (ArticleList, error) {
+	articlesMu.Lock()
+	defer articlesMu.Unlock()
+
+	if feedFile == "" {
+		if articlesGen == 0 {
+			articlesGen++
+			data, err := contentFS.ReadFile("content/feed.json")
+			if err == nil {
+				articles, err = ParseFeed(data)
+			}
+			if err != nil {
+				articlesErr = fmt.Errorf("content/feed.json: %w", err)
+			}
+		}
+		return articles, articlesErr
+	}
+
+	info, err := os.Stat(feedFile)
+	if err == nil && info.ModTime().Equal(feedModTime) && info.Size() == feedSize {
+		return articles, articlesErr
+	}
+	var list ArticleList
+	if err == nil {
+		feedModTime, feedSize = info.ModTime(), info.Size()
+		var data []byte
+		if data, err = os.ReadFile(feedFile); err == nil {
+			list, err = ParseFeed(data)
+		}
+	}
+	switch {
+	case err == nil:
+		articles, articlesErr = list, nil
+		articlesGen++
+	case articlesGen == 0:
+		articlesErr = fmt.Errorf("%s: %w", feedFile, err)
+	}
+	return articles, articlesErr
+}
+
+// articlesVersion tells apart the sets of articles LoadArticles has
+// returned, so what is built from them can be rebuilt when they change.
+func articlesVersion() int {
+	articlesMu.Lock()
+	defer articlesMu.Unlock()
+	return articlesGen
+}
+
+// slugify turns s into a lowercase name made of letters, digits and
+// dashes, for use as a file name.
+func slugify(s string) string {
+	var sb strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(s) {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9':
+			if dash && sb.Len() > 0 {
+				sb.WriteByte('-')
+			}
+			sb.WriteRune(r)
+			dash = false
+		default:
+			dash = true
+		}
+	}
+	return sb.String()
+}
+
+// htmlToMarkdown turns the HTML of a feed's summaries and bodies into the
+// Markdown the terminal renders. Paragraphs, headings, lists, quotes, code,
+// emphasis and links are kept; other tags are dropped along with their
+// markup. Text without tags comes back as it is, since some feeds carry
+// Markdown or plain text.
+func htmlToMarkdown(s string) string {
+	s = strings.TrimSpace(s)
+	if !strings.Contains(s, "<") {
+		return html.UnescapeString(s)
+	}
+
+	var sb strings.Builder
+	var hrefs []string // targets of the open links
+	var lists []int    // open lists: -1 for bullets, else the next number
+	pre, quote := false, 0
+	newline := func(n int) {
+		sb.WriteString(strings.Repeat("\n", n))
+		sb.WriteString(strings.Repeat("> ", quote))
+	}
+	for s != "" {
+		i := strings.IndexByte(s, '<')
+		if i < 0 {
+			i = len(s)
+		}
+		text := html.UnescapeString(s[:i])
+		if pre {
+			text = strings.ReplaceAll(text, "\n", "\n"+strings.Repeat("> ", quote))
+		} else {
+			text = strings.Join(strings.Fields(text), " ")
+			if t := s[:i]; text != "" && strings.TrimLeft(t, " \t\r\n") != t {
+				text = " " + text
+			}
+			if t := s[:i]; text != "" && strings.TrimRight(t, " \t\r\n") != t {
+				text += " "
+			}
+		}
+		sb.WriteString(text)
+		if i == len(s) {
+			break
+		}
+		j := strings.IndexByte(s[i:], '>')
+		if j < 0 {
+			sb.WriteString(html.UnescapeString(s[i:]))
+			break
+		}
+		tag := s[i+1 : i+j]
+		s = s[i+j+1:]
+
+		closing := strings.HasPrefix(tag, "/")
+		name, attrs, _ := strings.Cut(strings.TrimPrefix(tag, "/"), " ")
+		switch name = strings.ToLower(strings.TrimSuffix(name, "/")); name {
+		case "p", "div":
+			newline(2)
+		case "br":
+			newline(1)
+		case "h1", "h2", "h3", "h4", "h5", "h6":
+			newline(2)
+			if !closing {
+				sb.WriteString(strings.Repeat("#", int(name[1]-'0')) + " ")
+			}
+		case "strong", "b":
+			sb.WriteString("**")
+		case "em", "i":
+			sb.WriteString("_")
+		case "code":
+			if !pre {
+				sb.WriteString("`")
+			}
+		case "pre":
+			pre = !closing
+			if closing {
+				newline(1)
+				sb.WriteString("```")
+				newline(2)
+			} else {
+				newline(2)
+				sb.WriteString("```")
+				newline(1)
+			}
+		case "blockquote":
+			if closing {
+				quote = max(quote-1, 0)
+			} else {
+				quote++
+			}
+			newline(2)
+		case "ul", "ol":
+			switch {
+			case closing && len(lists) > 0:
+				lists = lists[:len(lists)-1]
+			case name == "ul":
+				lists = append(lists, -1)
+			default:
+				lists = append(lists, 1)
+			}
+			newline(2)
+		case "li":
+			if closing || len(lists) == 0 {
+				break
+			}
+			newline(1)
+			sb.WriteString(strings.Repeat("  ", len(lists)-1))
+			if n := &lists[len(lists)-1]; *n < 0 {
+				sb.WriteString("- ")
+			} else {
+				sb.WriteString(strconv.Itoa(*n) + ". ")
+				*n++
+			}
+		case "a":
+			if !closing {
+				hrefs = append(hrefs, htmlAttr(attrs, "href"))
+				sb.WriteString("[")
+			} else if len(hrefs) > 0 {
+				sb.WriteString("](" + hrefs[len(hrefs)-1] + ")")
+				hrefs = hrefs[:len(hrefs)-1]
+			}
+		case "img":
+			sb.WriteString(htmlAttr(attrs, "alt"))
+		}
+	}
+
+	// Tidy the blank lines and spaces left around the block tags
+	var out []string
+	blank, fenced := false, false
+	for _, line := range strings.Split(sb.String(), "\n") {
+		if !fenced {
+			line = strings.TrimRight(line, " ")
+			if lead := strings.TrimLeft(line, " "); !strings.HasPrefix(lead, "- ") && !startsNumbered(lead) {
+				line = lead
+			}
+		}
+		if strings.HasPrefix(strings.TrimLeft(line, "> "), "```") {
+			fenced = !fenced
+		}
+		if strings.Trim(line, "> ") == "" && !fenced {
+			blank = len(out) > 0
+			continue
+		}
+		if blank {
+			out = append(out, "")
+			blank = false
+		}
+		out = append(out, line)
+	}
+	return strings.Join(out, "\n")
+}
+
+// startsNumbered reports whether line starts with a numbered list marker.
+func startsNumbered(line string) bool {
+	digits := strings.TrimLeft(line, "0123456789")
+	return len(digits) < len(line) && strings.HasPrefix(digits, ". ")
+}
+
+// htmlAttr returns the value of the named attribute in the attributes of a
+// tag, quoted or not.
+func htmlAttr(attrs, name string) string {
+	for attrs != "" {
+		attrs = strings.TrimLeft(attrs, " \t\r\n")
+		key, rest, ok := strings.Cut(attrs, "=")
+		if !ok {
+			return ""
+		}
+		rest = strings.TrimLeft(rest, " ")
+		var value string
+		if rest != "" && (rest[0] == '"' || rest[0] == '\'') {
+			end := strings.IndexByte(rest[1:], rest[0])
+			if end < 0 {
+				return ""
+			}
+			value, attrs = rest[1:end+1], rest[end+2:]
+		} else {
+			value, attrs, _ = strings.Cut(rest, " ")
+		}
+		if strings.EqualFold(strings.TrimSpace(key), name) {
+			return html.UnescapeString(value)
+		}
+	}
+	return ""
+}
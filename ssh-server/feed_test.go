@@ -0,0 +1,94 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseFeed(t *testing.T) {
+	tests := []struct {
+		name, src string
+		want      Article
+	}{
+		{
+			name: "rss",
+			src: `<?xml version="1.0"?>
+<rss version="2.0" xmlns:content="http://purl.org/rss/1.0/modules/content/">
+<channel>
+  <title>Blog</title>
+  <item>
+    <title>Older post</title>
+    <link>https://yourblog.com/older</link>
+    <pubDate>Mon, 03 Jun 2024 09:00:00 +0000</pubDate>
+  </item>
+  <item>
+    <title>Newer post</title>
+    <link>https://yourblog.com/newer</link>
+    <pubDate>Sat, 2 Nov 2024 09:00:00 GMT</pubDate>
+    <description>A &lt;em&gt;short&lt;/em&gt; summary.</description>
+    <content:encoded><![CDATA[<p>First.</p><ul><li>one</li><li>two</li></ul>]]></content:encoded>
+  </item>
+</channel>
+</rss>`,
+			want: Article{
+				Title:   "Newer post",
+				URL:     "https://yourblog.com/newer",
+				Date:    time.Date(2024, 11, 2, 9, 0, 0, 0, time.UTC),
+				Summary: "A _short_ summary.",
+				Body:    "First.\n\n- one\n- two",
+			},
+		},
+		{
+			name: "atom",
+			src: `<feed xmlns="http://www.w3.org/2005/Atom">
+  <entry>
+    <title>Atom post</title>
+    <link rel="self" href="https://yourblog.com/feed/atom-post"/>
+    <link rel="alternate" href="https://yourblog.com/atom-post"/>
+    <updated>2024-06-18T09:00:00Z</updated>
+    <summary>Plain summary</summary>
+    <content type="html">&lt;p&gt;See &lt;a href="https://genar.me"&gt;the site&lt;/a&gt;.&lt;/p&gt;</content>
+  </entry>
+</feed>`,
+			want: Article{
+				Title:   "Atom post",
+				URL:     "https://yourblog.com/atom-post",
+				Date:    time.Date(2024, 6, 18, 9, 0, 0, 0, time.UTC),
+				Summary: "Plain summary",
+				Body:    "See [the site](https://genar.me).",
+			},
+		},
+		{
+			name: "json feed",
+			src:  `{"items": [{"url": "https://yourblog.com/json", "title": "JSON post", "date_published": "2024-01-05T00:00:00Z", "content_html": "<pre><code>go test ./...</code></pre>"}]}`,
+			want: Article{
+				Title: "JSON post",
+				URL:   "https://yourblog.com/json",
+				Date:  time.Date(2024, 1, 5, 0, 0, 0, 0, time.UTC),
+				Body:  "```\ngo test ./...\n```",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			list, err := ParseFeed([]byte(tt.src))
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := list[0]
+			if !got.Date.Equal(tt.want.Date) {
+				t.Errorf("date = %v, want %v", got.Date, tt.want.Date)
+			}
+			got.Date = tt.want.Date
+			if got != tt.want {
+				t.Errorf("got %+v\nwant %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseFeedUnsupported(t *testing.T) {
+	if _, err := ParseFeed([]byte(`<html><body></body></html>`)); err == nil {
+		t.Error("expected an error for a document that is not a feed")
+	}
+}
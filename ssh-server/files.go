@@ -0,0 +1,214 @@
+package main
+
+import (
+	"errors"
+	"strings"
+	"sync"
+
+	"github.com/genar/genar.me-ssh/vfs"
+)
+
+// homeDir is where the file commands start, as in the website's shell.
+const homeDir = "/home/genar"
+
+var (
+	filesMu     sync.Mutex
+	loadedFiles = map[Locale]loadedFS{}
+)
+
+// loadedFS is a built filesystem and the version of the articles in it.
+type loadedFS struct {
+	fs       *vfs.FS
+	articles int
+}
+
+// LoadFiles builds the filesystem the file commands browse, laid out like
+// the website's shell: the portfolio under /home/genar as Markdown files,
+// with /etc and /usr around it. Paths are the same in every language; only
+// the files' text is translated. It is built again when the feed changes.
+func LoadFiles(l Locale) (*vfs.FS, error) {
+	filesMu.Lock()
+	defer filesMu.Unlock()
+	// An unreadable feed leaves ~/articles empty rather than every file
+	// missing
+	posts, _ := LoadArticles()
+	version := articlesVersion()
+	if f, ok := loadedFiles[l]; ok && f.articles == version {
+		return f.fs, nil
+	}
+
+	c, err := LoadContentFor(l)
+	if err != nil {
+		return nil, err
+	}
+	t := CatalogFor(l)
+
+	projects := vfs.Dir("projects")
+	for _, p := range c.Projects {
+		projects.Add(vfs.File(p.ID+".md", p.Markdown(t)))
+	}
+	work := vfs.Dir("work")
+	for _, exp := range c.Experience {
+		work.Add(vfs.File(slugify(exp.Company)+".md", workMarkdown(exp)))
+	}
+	articles := vfs.Dir("articles")
+	for _, a := range posts {
+		articles.Add(vfs.File(a.Slug()+".md", a.Markdown()))
+	}
+	bin := vfs.Dir("bin")
+	for _, cmd := range GetAllCommands() {
+		bin.Add(vfs.File(cmd.Name, cmd.Name+" - "+t.Describe(cmd)+"\n"))
+	}
+
+	root := vfs.Dir("/",
+		vfs.Dir("home", vfs.Dir("genar",
+			vfs.File("about.md", c.Profile.Markdown(t)),
+			projects,
+			work,
+			articles,
+		)),
+		vfs.Dir("etc",
+			vfs.File("hostname", "genar.me\n"),
+			vfs.File("motd", t.T("welcome.tagline")+"\n"+t.T("welcome.hint")+"\n"),
+		),
+		vfs.Dir("usr", bin),
+	)
+	f := vfs.New(root, homeDir)
+	loadedFiles[l] = loadedFS{fs: f, articles: version}
+	return f, nil
+}
+
+// workMarkdown is the file of a position in ~/work.
+func workMarkdown(exp Experience) string {
+	var sb strings.Builder
+	sb.WriteString("# " + exp.Role + "\n\n")
+	company := exp.Company
+	if exp.URL != "" {
+		company = "[" + company + "](" + markdownURL(exp.URL) + ")"
+	}
+	sb.WriteString("**" + company + "** | _" + exp.Period + "_\n\n")
+	sb.WriteString(exp.Description + "\n")
+	return sb.String()
+}
+
+// workingDir returns the session's working directory in f.
+func workingDir(ctx *CommandContext, f *vfs.FS) string {
+	if ctx.Dir == "" {
+		return f.Home()
+	}
+	return ctx.Dir
+}
+
+// lookupFile resolves p against the working directory and finds it,
+// turning a failure into an error for the visitor.
+func lookupFile(ctx *CommandContext, f *vfs.FS, p string) (string, *vfs.Node, error) {
+	t := ctx.Catalog
+	abs := f.Resolve(workingDir(ctx, f), p)
+	n, err := f.Lookup(abs)
+	switch {
+	case errors.Is(err, vfs.ErrNotDir):
+		return "", nil, errors.New(t.T("files.not_dir", p))
+	case err != nil:
+		return "", nil, errors.New(t.T("files.not_found", p))
+	}
+	return abs, n, nil
+}
+
+// lsCommand lists a directory, the working one by default (`ls ~/projects`).
+func lsCommand(ctx *CommandContext) (string, error) {
+	st, t := ctx.Styles, ctx.Catalog
+	if len(ctx.Args) > 1 {
+		return "", &UsageError{t.T("ls.usage")}
+	}
+	f, err := LoadFiles(t.Locale)
+	if err != nil {
+		return "", err
+	}
+	p := workingDir(ctx, f)
+	if len(ctx.Args) == 1 {
+		p = ctx.Args[0]
+	}
+	_, n, err := lookupFile(ctx, f, p)
+	if err != nil {
+		return "", err
+	}
+
+	// A file lists as itself
+	entries := []*vfs.Node{n}
+	if n.IsDir() {
+		entries = n.Children()
+	}
+	names := make([]string, 0, len(entries))
+	for _, e := range entries {
+		switch {
+		case e.IsDir():
+			names = append(names, st.Label(e.Name()+"/"))
+		default:
+			names = append(names, st.Value(e.Name()))
+		}
+	}
+
+	// One name per line when piped or read out, like ls does
+	if ctx.Format == FormatPlain || st.Accessible {
+		return strings.Join(names, "\n"), nil
+	}
+	return strings.Join(names, "  "), nil
+}
+
+// cdCommand changes the session's working directory, home by default.
+func cdCommand(ctx *CommandContext) (string, error) {
+	t := ctx.Catalog
+	if len(ctx.Args) > 1 {
+		return "", &UsageError{t.T("cd.usage")}
+	}
+	f, err := LoadFiles(t.Locale)
+	if err != nil {
+		return "", err
+	}
+	p := ""
+	if len(ctx.Args) == 1 {
+		p = ctx.Args[0]
+	}
+	abs, n, err := lookupFile(ctx, f, p)
+	if err != nil {
+		return "", err
+	}
+	if !n.IsDir() {
+		return "", errors.New(t.T("files.not_dir", p))
+	}
+	ctx.Dir = abs
+	return ctx.Styles.Value(f.Short(abs)), nil
+}
+
+// catCommand prints a file (`cat ~/projects/ssh-portfolio.md`). Markdown
+// files are rendered for the terminal, and printed as they are when piped.
+func catCommand(ctx *CommandContext) (string, error) {
+	t := ctx.Catalog
+	if len(ctx.Args) != 1 {
+		return "", &UsageError{t.T("cat.usage")}
+	}
+	f, err := LoadFiles(t.Locale)
+	if err != nil {
+		return "", err
+	}
+	_, n, err := lookupFile(ctx, f, ctx.Args[0])
+	if err != nil {
+		return "", err
+	}
+	if n.IsDir() {
+		return "", errors.New(t.T("files.is_dir", ctx.Args[0]))
+	}
+	if strings.HasSuffix(n.Name(), ".md") && ctx.Format != FormatPlain {
+		return RenderMarkdown(n.Content(), ctx.Styles, markdownWidth(ctx.Width)), nil
+	}
+	return strings.TrimSuffix(n.Content(), "\n"), nil
+}
+
+// pwdCommand prints the session's working directory.
+func pwdCommand(ctx *CommandContext) (string, error) {
+	f, err := LoadFiles(ctx.Catalog.Locale)
+	if err != nil {
+		return "", err
+	}
+	return workingDir(ctx, f), nil
+}
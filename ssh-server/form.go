@@ -0,0 +1,268 @@
+package main
+
+import (
+	"strconv"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// Form is a set of text fields a command asks the TUI to fill in, such as
+// the message of `contact`. Outside the TUI the command's output is shown
+// instead, so it should say how to pass the values as flags.
+type Form struct {
+	Title  string
+	Fields []FormField
+	Submit string // label of the submit button
+
+	// Validate returns a message for each field whose value is wrong,
+	// keyed by field name; Send runs once there are none and returns the
+	// output shown afterwards.
+	Validate func(values map[string]string) map[string]string
+	Send     func(values map[string]string) (string, error)
+}
+
+// FormField is one text field of a form.
+type FormField struct {
+	Name      string // key in the values
+	Label     string
+	Value     string
+	Max       int  // runes
+	Lines     int  // rows shown; long values wrap within them
+	Multiline bool // Enter starts a new line instead of moving on
+}
+
+// values returns the fields' values by name.
+func (f *Form) values() map[string]string {
+	values := make(map[string]string, len(f.Fields))
+	for _, field := range f.Fields {
+		values[field.Name] = field.Value
+	}
+	return values
+}
+
+// formWidth is the width of a form's text fields.
+const formWidth = 56
+
+// openForm shows form instead of the output of the command that asked
+// for it.
+func (m *Model) openForm(form *Form) {
+	m.form = form
+	m.mode = FormMode
+	m.formField = 0
+	m.formErrors = nil
+	m.formStatus = ""
+}
+
+// closeForm leaves the form for the menu, dropping what was typed.
+func (m *Model) closeForm() {
+	m.form = nil
+	m.mode = MenuMode
+	m.formErrors = nil
+	m.formStatus = ""
+}
+
+// updateForm handles keys while a form is open. Tab and the arrow keys
+// move between the fields and the submit button, Enter moves on or sends,
+// except in multi-line fields where it starts a new line, and Ctrl+S sends
+// from anywhere.
+func (m Model) updateForm(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	f := m.form
+	last := len(f.Fields) // the submit button
+	switch msg.Type {
+	case tea.KeyCtrlC:
+		return m, tea.Quit
+	case tea.KeyEsc:
+		m.closeForm()
+	case tea.KeyTab, tea.KeyDown:
+		m.formField = (m.formField + 1) % (last + 1)
+	case tea.KeyShiftTab, tea.KeyUp:
+		m.formField = (m.formField + last) % (last + 1)
+	case tea.KeyCtrlS:
+		m.submitForm()
+	case tea.KeyEnter:
+		switch {
+		case m.formField == last:
+			m.submitForm()
+		case f.Fields[m.formField].Multiline:
+			f.Fields[m.formField].insert("\n")
+		default:
+			m.formField++
+		}
+	case tea.KeyBackspace:
+		if m.formField < last {
+			field := &f.Fields[m.formField]
+			if r := []rune(field.Value); len(r) > 0 {
+				field.Value = string(r[:len(r)-1])
+			}
+		}
+	case tea.KeySpace, tea.KeyRunes:
+		if m.formField < last {
+			text := " "
+			if msg.Type == tea.KeyRunes {
+				text = string(msg.Runes)
+			}
+			f.Fields[m.formField].insert(text)
+		}
+	}
+	return m, nil
+}
+
+// insert adds typed or pasted text to the end of the field's value, up to
+// its length limit. Pasted text may bring escape sequences and line breaks,
+// which only multi-line fields keep.
+func (field *FormField) insert(text string) {
+	text = strings.NewReplacer("\r\n", "\n", "\r", "\n", "\t", " ").Replace(ansi.Strip(text))
+	if !field.Multiline {
+		text = strings.ReplaceAll(text, "\n", " ")
+	}
+	value := []rune(field.Value + text)
+	if field.Max > 0 && len(value) > field.Max {
+		value = value[:field.Max]
+	}
+	field.Value = string(value)
+}
+
+// submitForm validates the form and sends it, showing the result as the
+// output of the command that opened it.
+func (m *Model) submitForm() {
+	f := m.form
+	values := f.values()
+	m.formErrors = f.Validate(values)
+	if len(m.formErrors) > 0 {
+		for i, field := range f.Fields {
+			if m.formErrors[field.Name] != "" {
+				m.formField = i
+				break
+			}
+		}
+		return
+	}
+
+	output, err := f.Send(values)
+	if err != nil {
+		m.formStatus = m.catalog.T("error.prefix", err.Error())
+		return
+	}
+	m.form = nil
+	m.formErrors = nil
+	m.formStatus = ""
+	m.output = output
+	m.mode = ContentMode
+	m.scroll = 0
+	m.links = nil
+	m.link = -1
+}
+
+// clickForm focuses the field or button with the given zone ID.
+func (m *Model) clickForm(kind, arg string) {
+	switch kind {
+	case "field":
+		if i, err := strconv.Atoi(arg); err == nil && i < len(m.form.Fields) {
+			m.formField = i
+		}
+	case "submit":
+		m.formField = len(m.form.Fields)
+		m.submitForm()
+	case "back":
+		m.closeForm()
+	}
+}
+
+// renderForm renders the fields with their labels and errors, then the
+// submit button. In accessible mode fields are plain lines, followed by an
+// announcement of the focused one.
+func (m Model) renderForm() string {
+	st, t, f := m.styles, m.catalog, m.form
+	var sb strings.Builder
+
+	sb.WriteString(st.Header(strings.ToUpper(f.Title)))
+	sb.WriteString("\n\n")
+	for i, field := range f.Fields {
+		focused := i == m.formField
+		errText := m.formErrors[field.Name]
+		if st.Accessible {
+			line := field.Label + " " + field.Value
+			if focused {
+				line += t.T("menu.selected")
+			}
+			sb.WriteString(line + "\n")
+			if errText != "" {
+				sb.WriteString(t.T("error.prefix", errText) + "\n")
+			}
+			continue
+		}
+
+		label := st.Label(field.Label)
+		if focused {
+			label = st.Prompt.Render("▸ ") + label
+		} else {
+			label = "  " + label
+		}
+		sb.WriteString(zoneMark("field:"+strconv.Itoa(i), label) + "\n")
+
+		value := field.Value
+		if focused {
+			value += "█"
+		}
+		lines := strings.Split(ansi.Wrap(value, formWidth, " "), "\n")
+		rows := max(field.Lines, 1)
+		if len(lines) > rows {
+			lines = lines[len(lines)-rows:] // keep the end, where typing goes
+		}
+		for len(lines) < rows {
+			lines = append(lines, "")
+		}
+		box := st.FormField
+		if focused {
+			box = st.FormFieldFocused
+		}
+		sb.WriteString(zoneMark("field:"+strconv.Itoa(i), box.Width(formWidth+2).MarginLeft(2).Render(strings.Join(lines, "\n"))) + "\n")
+		if errText != "" {
+			sb.WriteString("  " + st.Error.Render(errText) + "\n")
+		}
+		sb.WriteString("\n")
+	}
+
+	submit := "[ " + f.Submit + " ]"
+	switch {
+	case st.Accessible:
+		if m.formField == len(f.Fields) {
+			submit += t.T("menu.selected")
+		}
+	case m.formField == len(f.Fields):
+		submit = st.LinkSelected.Render(submit)
+	case m.hover == "submit":
+		submit = st.LinkHover.Render(submit)
+	}
+	sb.WriteString("\n  " + zoneMark("submit", submit) + "\n")
+	if m.formStatus != "" {
+		sb.WriteString("\n  " + st.Error.Render(m.formStatus) + "\n")
+	}
+
+	if st.Accessible {
+		focus := f.Submit
+		if m.formField < len(f.Fields) {
+			focus = f.Fields[m.formField].Label
+		}
+		sb.WriteString("\n" + t.T("form.announce", focus, m.formField+1, len(f.Fields)+1) + "\n")
+	}
+	return sb.String()
+}
+
+// renderFormFooter renders the back button and the key help under a form.
+func (m Model) renderFormFooter() string {
+	st, t := m.styles, m.catalog
+	var sb strings.Builder
+	sb.WriteString("\n")
+	if !st.Accessible {
+		back := st.Prompt.Render(t.T("content.back"))
+		if m.hover == "back" {
+			back = st.LinkHover.Inherit(st.Prompt).Render(t.T("content.back"))
+		}
+		sb.WriteString("  " + zoneMark("back", back) + "\n")
+	}
+	sb.WriteString(st.Help.Render(t.T("form.help")))
+	return sb.String()
+}
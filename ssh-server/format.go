@@ -0,0 +1,173 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Format selects how a command's output is rendered.
+type Format string
+
+const (
+	FormatStyled   Format = "styled"
+	FormatPlain    Format = "plain"
+	FormatJSON     Format = "json"
+	FormatYAML     Format = "yaml"
+	FormatMarkdown Format = "markdown"
+)
+
+// Formats lists every supported output format.
+var Formats = []Format{FormatStyled, FormatPlain, FormatJSON, FormatYAML, FormatMarkdown}
+
+// ParseFormat parses a format name, accepting a few common aliases.
+func ParseFormat(name string) (Format, error) {
+	switch strings.ToLower(name) {
+	case "styled", "ansi":
+		return FormatStyled, nil
+	case "plain", "text", "txt":
+		return FormatPlain, nil
+	case "json":
+		return FormatJSON, nil
+	case "yaml", "yml":
+		return FormatYAML, nil
+	case "markdown", "md":
+		return FormatMarkdown, nil
+	}
+	return "", fmt.Errorf("unknown format %q (want one of %s)", name, formatNames())
+}
+
+// ContentType returns the MIME type used when serving f over HTTP.
+func (f Format) ContentType() string {
+	switch f {
+	case FormatJSON:
+		return "application/json; charset=utf-8"
+	case FormatYAML:
+		return "application/yaml; charset=utf-8"
+	case FormatMarkdown:
+		return "text/markdown; charset=utf-8"
+	}
+	return "text/plain; charset=utf-8"
+}
+
+func formatNames() string {
+	names := make([]string, len(Formats))
+	for i, f := range Formats {
+		names[i] = string(f)
+	}
+	return strings.Join(names, ", ")
+}
+
+// Document is the data model behind a portfolio command. It renders itself
+// for humans; JSON and YAML are produced by marshalling it directly, so its
+// fields need json and yaml tags.
+type Document interface {
+	Styled(st *Styles, t *Catalog) string
+	Plain(t *Catalog) string
+	Markdown(t *Catalog) string
+}
+
+// AccessibleDocument is implemented by documents whose plain rendering is
+// a table or a raw file format. Accessible returns labeled, linear text for
+// screen readers instead; other documents use Plain in accessible mode.
+type AccessibleDocument interface {
+	Accessible(t *Catalog) string
+}
+
+// RenderDocument renders doc in the given format, with headings and labels
+// from catalog t. Styled output falls back to accessible text when the
+// session is in accessible mode.
+func RenderDocument(doc Document, f Format, st *Styles, t *Catalog) (string, error) {
+	switch f {
+	case FormatStyled, "":
+		if st != nil && st.Accessible {
+			if a, ok := doc.(AccessibleDocument); ok {
+				return a.Accessible(t), nil
+			}
+			return doc.Plain(t), nil
+		}
+		return doc.Styled(st, t), nil
+	case FormatPlain:
+		return doc.Plain(t), nil
+	case FormatMarkdown:
+		return doc.Markdown(t), nil
+	case FormatJSON:
+		data, err := json.MarshalIndent(doc, "", "  ")
+		if err != nil {
+			return "", err
+		}
+		return string(data), nil
+	case FormatYAML:
+		data, err := yaml.Marshal(doc)
+		if err != nil {
+			return "", err
+		}
+		return string(data), nil
+	}
+	return "", fmt.Errorf("unsupported format %q", f)
+}
+
+// jsonList encodes list as a JSON array, empty rather than null when there
+// is nothing in it. Documents that are lists under the hood use it.
+func jsonList[T any](list []T) ([]byte, error) {
+	if list == nil {
+		list = []T{}
+	}
+	return json.Marshal(list)
+}
+
+// runDocument parses the output flags shared by every document command
+// (--format and its --json shorthand) and the command's own, then builds
+// and renders the document. Flags may come before or after arguments.
+func runDocument(cmd Command, ctx *CommandContext) (string, error) {
+	fs := flag.NewFlagSet(cmd.Name, flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	formatName := fs.String("format", string(ctx.Format), "output format")
+	asJSON := fs.Bool("json", false, "shorthand for --format json")
+	flags := make(map[string]*string, len(cmd.Flags))
+	for _, name := range cmd.Flags {
+		flags[name] = fs.String(name, "", name)
+	}
+
+	var args []string
+	for rest := ctx.Args; ; {
+		if err := fs.Parse(rest); err != nil {
+			return "", &UsageError{cmd.Name + ": " + err.Error()}
+		}
+		if fs.NArg() == 0 {
+			break
+		}
+		args = append(args, fs.Arg(0))
+		rest = fs.Args()[1:]
+	}
+	if len(args) > 0 && !cmd.Args {
+		return "", &UsageError{fmt.Sprintf("%s: unexpected argument %q", cmd.Name, args[0])}
+	}
+	ctx.Args = args
+	ctx.Flags = make(map[string]string, len(flags))
+	for name, value := range flags {
+		ctx.Flags[name] = *value
+	}
+
+	format := FormatStyled
+	if *formatName != "" {
+		f, err := ParseFormat(*formatName)
+		if err != nil {
+			return "", &UsageError{cmd.Name + ": " + err.Error()}
+		}
+		format = f
+	}
+	if *asJSON {
+		format = FormatJSON
+	}
+
+	doc, err := cmd.Document(ctx)
+	if err != nil {
+		return "", err
+	}
+	return RenderDocument(doc, format, ctx.Styles, ctx.Catalog)
+}
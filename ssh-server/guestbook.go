@@ -0,0 +1,387 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/charmbracelet/x/ansi"
+)
+
+// Guestbook limits. Rate limits count the entries each key has in the
+// store, so they hold across restarts.
+const (
+	guestbookFile       = "guestbook.json"
+	guestbookMaxMessage = 280              // runes per message
+	guestbookMaxName    = 32               // runes per name
+	guestbookKeep       = 500              // entries kept, the oldest are dropped
+	guestbookShown      = 20               // entries listed by `guestbook`
+	guestbookInterval   = 10 * time.Minute // between two entries from one key
+	guestbookDaily      = 3                // entries per key in 24 hours
+)
+
+var (
+	ErrNoKey        = errors.New("signing needs an SSH key")
+	ErrEmptyMessage = errors.New("empty message")
+	ErrNoEntry      = errors.New("no such entry")
+)
+
+// RateLimitError is returned when a key signs again too soon.
+type RateLimitError struct {
+	Wait time.Duration // until the key may sign again
+}
+
+func (e *RateLimitError) Error() string {
+	return fmt.Sprintf("signed too often, wait %s", e.Wait.Round(time.Second))
+}
+
+// GuestbookEntry is a message left by a visitor, attributed to their SSH
+// login name and key.
+type GuestbookEntry struct {
+	ID      int       `json:"id" yaml:"id"`
+	Name    string    `json:"name" yaml:"name"`
+	Key     string    `json:"key" yaml:"key"` // SHA256 fingerprint of the key that signed
+	Message string    `json:"message" yaml:"message"`
+	Time    time.Time `json:"time" yaml:"time"`
+	Hidden  bool      `json:"hidden,omitempty" yaml:"hidden,omitempty"` // hidden by a moderator
+}
+
+// Guestbook stores the entries in a JSON file in the data directory.
+type Guestbook struct {
+	mu      sync.Mutex
+	file    string
+	entries []GuestbookEntry // oldest first
+	nextID  int              // never reused, even once its entry is gone
+	now     func() time.Time
+}
+
+// guestbookStore is the guestbook file.
+type guestbookStore struct {
+	NextID  int              `json:"next_id"`
+	Entries []GuestbookEntry `json:"entries"`
+}
+
+// OpenGuestbook loads the guestbook stored in file.
+func OpenGuestbook(file string) (*Guestbook, error) {
+	var store guestbookStore
+	if err := loadJSON(file, &store); err != nil {
+		return nil, err
+	}
+	return &Guestbook{file: file, entries: store.Entries, nextID: max(store.NextID, 1), now: time.Now}, nil
+}
+
+// save stores entries along with the next ID. g.mu must be held.
+func (g *Guestbook) save(entries []GuestbookEntry, nextID int) error {
+	return saveJSON(g.file, guestbookStore{NextID: nextID, Entries: entries})
+}
+
+var (
+	guestbookOnce sync.Once
+	guestbook     *Guestbook
+	guestbookErr  error
+)
+
+// LoadGuestbook opens the server's guestbook, shared by every session.
+func LoadGuestbook() (*Guestbook, error) {
+	guestbookOnce.Do(func() {
+		guestbook, guestbookErr = OpenGuestbook(guestbookFile)
+	})
+	return guestbook, guestbookErr
+}
+
+// Entries returns up to n entries, newest first, leaving out hidden ones
+// unless hidden is set.
+func (g *Guestbook) Entries(n int, hidden bool) []GuestbookEntry {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	var list []GuestbookEntry
+	for i := len(g.entries) - 1; i >= 0 && len(list) < n; i-- {
+		if e := g.entries[i]; hidden || !e.Hidden {
+			list = append(list, e)
+		}
+	}
+	return list
+}
+
+// Sign adds an entry from the visitor signed in as name with key. The
+// message is cleaned of escape sequences and profanity and cut to the
+// length limit.
+func (g *Guestbook) Sign(name, key, message string) (GuestbookEntry, error) {
+	if key == "" {
+		return GuestbookEntry{}, ErrNoKey
+	}
+	message = maskProfanity(sanitizeLine(message, guestbookMaxMessage))
+	if message == "" {
+		return GuestbookEntry{}, ErrEmptyMessage
+	}
+	name = maskProfanity(sanitizeLine(name, guestbookMaxName))
+	if name == "" {
+		name = "guest"
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	now := g.now()
+	if wait := g.wait(key, now); wait > 0 {
+		return GuestbookEntry{}, &RateLimitError{Wait: wait}
+	}
+
+	e := GuestbookEntry{ID: g.nextID, Name: name, Key: key, Message: message, Time: now}
+	entries := append(append([]GuestbookEntry(nil), g.entries...), e)
+	if len(entries) > guestbookKeep {
+		entries = entries[len(entries)-guestbookKeep:]
+	}
+	if err := g.save(entries, e.ID+1); err != nil {
+		return GuestbookEntry{}, err
+	}
+	g.entries = entries
+	g.nextID = e.ID + 1
+	return e, nil
+}
+
+// wait returns how long key has to wait before signing again.
+func (g *Guestbook) wait(key string, now time.Time) time.Duration {
+	var recent []time.Time // entries by key in the last day, newest first
+	for i := len(g.entries) - 1; i >= 0; i-- {
+		e := g.entries[i]
+		if now.Sub(e.Time) >= 24*time.Hour {
+			break
+		}
+		if e.Key == key {
+			recent = append(recent, e.Time)
+		}
+	}
+	var wait time.Duration
+	if len(recent) > 0 {
+		wait = recent[0].Add(guestbookInterval).Sub(now)
+	}
+	if len(recent) >= guestbookDaily {
+		wait = max(wait, recent[guestbookDaily-1].Add(24*time.Hour).Sub(now))
+	}
+	return wait
+}
+
+// SetHidden hides or shows the entry with the given ID.
+func (g *Guestbook) SetHidden(id int, hidden bool) error {
+	return g.update(id, func(entries []GuestbookEntry, i int) []GuestbookEntry {
+		entries[i].Hidden = hidden
+		return entries
+	})
+}
+
+// Delete removes the entry with the given ID.
+func (g *Guestbook) Delete(id int) error {
+	return g.update(id, func(entries []GuestbookEntry, i int) []GuestbookEntry {
+		return append(entries[:i], entries[i+1:]...)
+	})
+}
+
+// update applies change to a copy of the entries, with the index of the
+// one with the given ID, and stores the result.
+func (g *Guestbook) update(id int, change func(entries []GuestbookEntry, i int) []GuestbookEntry) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	for i, e := range g.entries {
+		if e.ID == id {
+			entries := change(append([]GuestbookEntry(nil), g.entries...), i)
+			if err := g.save(entries, g.nextID); err != nil {
+				return err
+			}
+			g.entries = entries
+			return nil
+		}
+	}
+	return ErrNoEntry
+}
+
+// guestbookDocument lists the latest entries, or signs the guestbook with
+// `guestbook sign <message>`.
+func guestbookDocument(ctx *CommandContext) (Document, error) {
+	t := ctx.Catalog
+	g, err := LoadGuestbook()
+	if err != nil {
+		return nil, err
+	}
+
+	v := guestbookView{canSign: ctx.Key != ""}
+	switch {
+	case len(ctx.Args) == 0:
+	case ctx.Args[0] == "sign" && len(ctx.Args) > 1:
+		e, err := g.Sign(ctx.User, ctx.Key, strings.Join(ctx.Args[1:], " "))
+		var limit *RateLimitError
+		switch {
+		case errors.Is(err, ErrNoKey):
+			return nil, errors.New(t.T("guestbook.no_key"))
+		case errors.Is(err, ErrEmptyMessage):
+			return nil, &UsageError{t.T("guestbook.usage")}
+		case errors.As(err, &limit):
+			wait := max(limit.Wait.Round(time.Minute), time.Minute)
+			return nil, errors.New(t.T("guestbook.rate_limited", strings.TrimSuffix(wait.String(), "0s")))
+		case err != nil:
+			return nil, err
+		}
+		v.signed = &e
+	default:
+		return nil, &UsageError{t.T("guestbook.usage")}
+	}
+	v.entries = g.Entries(guestbookShown, false)
+	return v, nil
+}
+
+// guestbookView is the latest entries. It is encoded as the bare list.
+type guestbookView struct {
+	entries []GuestbookEntry
+	signed  *GuestbookEntry // the entry just added, if any
+	canSign bool            // the visitor signed in with a key
+}
+
+// MarshalJSON encodes the entries.
+func (v guestbookView) MarshalJSON() ([]byte, error) {
+	return jsonList(v.entries)
+}
+
+// MarshalYAML encodes the entries.
+func (v guestbookView) MarshalYAML() (interface{}, error) {
+	if v.entries == nil {
+		return []GuestbookEntry{}, nil
+	}
+	return v.entries, nil
+}
+
+// hint tells the visitor how to sign, or why they can't.
+func (v guestbookView) hint(t *Catalog) string {
+	if v.canSign {
+		return t.T("guestbook.hint", guestbookMaxMessage)
+	}
+	return t.T("guestbook.hint_no_key")
+}
+
+// Styled renders the entries with their author, key and date.
+func (v guestbookView) Styled(st *Styles, t *Catalog) string {
+	var sb strings.Builder
+
+	sb.WriteString(st.Header(strings.ToUpper(t.T("guestbook.heading"))))
+	sb.WriteString("\n\n")
+	if v.signed != nil {
+		sb.WriteString(st.Label(t.T("guestbook.signed")) + "\n\n")
+	}
+	if len(v.entries) == 0 {
+		sb.WriteString(st.Dim(t.T("guestbook.none")) + "\n\n")
+	}
+	for _, e := range v.entries {
+		sb.WriteString(st.Label(e.Name) + "  " + st.Dim(shortFingerprint(e.Key)+" · "+e.Time.Format("2006-01-02")) + "\n")
+		sb.WriteString(st.Content.Render(ansi.Wordwrap(e.Message, 56, "")) + "\n")
+	}
+	sb.WriteString(st.Dim(v.hint(t)))
+
+	return sb.String()
+}
+
+// Plain renders the entries without styling.
+func (v guestbookView) Plain(t *Catalog) string {
+	var sb strings.Builder
+
+	sb.WriteString(strings.ToUpper(t.T("guestbook.heading")) + "\n")
+	if v.signed != nil {
+		sb.WriteString("\n" + t.T("guestbook.signed") + "\n")
+	}
+	if len(v.entries) == 0 {
+		sb.WriteString("\n" + t.T("guestbook.none") + "\n")
+	}
+	for _, e := range v.entries {
+		sb.WriteString("\n" + e.Name + " | " + e.Key + " | " + e.Time.Format("2006-01-02 15:04") + "\n")
+		sb.WriteString(e.Message + "\n")
+	}
+
+	return sb.String()
+}
+
+// Accessible renders each entry as labeled lines.
+func (v guestbookView) Accessible(t *Catalog) string {
+	var sb strings.Builder
+
+	sb.WriteString(strings.ToUpper(t.T("guestbook.heading")) + ", " + t.T("guestbook.count", len(v.entries)) + "\n")
+	if v.signed != nil {
+		sb.WriteString(t.T("guestbook.signed") + "\n")
+	}
+	for _, e := range v.entries {
+		sb.WriteString("\n" + t.T("field.name") + " " + e.Name + "\n")
+		sb.WriteString(t.T("date.date") + " " + longDate(t, e.Time) + "\n")
+		sb.WriteString(e.Message + "\n")
+	}
+	sb.WriteString("\n" + v.hint(t) + "\n")
+
+	return sb.String()
+}
+
+// Markdown renders the entries as Markdown, quoting each message.
+func (v guestbookView) Markdown(t *Catalog) string {
+	var sb strings.Builder
+
+	sb.WriteString("# " + t.T("guestbook.heading") + "\n")
+	for _, e := range v.entries {
+		sb.WriteString("\n**" + e.Name + "** `" + e.Key + "` _" + e.Time.Format("2006-01-02") + "_\n\n")
+		sb.WriteString("> " + e.Message + "\n")
+	}
+
+	return sb.String()
+}
+
+// moderateCommand lets admins review every recent guestbook entry, hidden
+// ones included, and hide, show or delete them.
+func moderateCommand(ctx *CommandContext) (string, error) {
+	st, t := ctx.Styles, ctx.Catalog
+	if !IsAdmin(ctx.Key) {
+		return "", errors.New(t.T("admin.denied"))
+	}
+	g, err := LoadGuestbook()
+	if err != nil {
+		return "", err
+	}
+
+	if len(ctx.Args) == 0 {
+		var sb strings.Builder
+		sb.WriteString(st.Header(strings.ToUpper(t.T("moderate.heading"))) + "\n\n")
+		entries := g.Entries(guestbookKeep, true)
+		if len(entries) == 0 {
+			sb.WriteString(st.Dim(t.T("guestbook.none")) + "\n")
+		}
+		for _, e := range entries {
+			line := st.Label("#"+strconv.Itoa(e.ID)) + " " + st.Value(e.Name) + " " + st.Dim(e.Key+" "+e.Time.Format("2006-01-02 15:04"))
+			if e.Hidden {
+				line += " " + st.Error.Render(t.T("moderate.hidden"))
+			}
+			sb.WriteString(line + "\n  " + e.Message + "\n")
+		}
+		sb.WriteString("\n" + st.Dim(t.T("moderate.usage")))
+		return sb.String(), nil
+	}
+
+	if len(ctx.Args) != 2 {
+		return "", &UsageError{t.T("moderate.usage")}
+	}
+	id, err := strconv.Atoi(strings.TrimPrefix(ctx.Args[1], "#"))
+	if err != nil {
+		return "", &UsageError{t.T("moderate.usage")}
+	}
+	switch ctx.Args[0] {
+	case "hide":
+		err = g.SetHidden(id, true)
+	case "show":
+		err = g.SetHidden(id, false)
+	case "delete":
+		err = g.Delete(id)
+	default:
+		return "", &UsageError{t.T("moderate.usage")}
+	}
+	if errors.Is(err, ErrNoEntry) {
+		return "", errors.New(t.T("moderate.unknown", id))
+	}
+	if err != nil {
+		return "", err
+	}
+	return st.Label(t.T("moderate.done."+ctx.Args[0], id)), nil
+}
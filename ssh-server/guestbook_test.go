@@ -0,0 +1,86 @@
+package main
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestGuestbookSign(t *testing.T) {
+	dataDir = t.TempDir()
+	g, err := OpenGuestbook(guestbookFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	g.now = func() time.Time { return now }
+
+	if _, err := g.Sign("alice", "", "hi"); !errors.Is(err, ErrNoKey) {
+		t.Errorf("signing without a key: err = %v, want ErrNoKey", err)
+	}
+	if _, err := g.Sign("alice", "SHA256:a", "\x1b[2J\x07 "); !errors.Is(err, ErrEmptyMessage) {
+		t.Errorf("signing with only escapes: err = %v, want ErrEmptyMessage", err)
+	}
+
+	e, err := g.Sign("alice\x1b[31m", "SHA256:a", "Nice \x1b[1mshit\x1b[0m,\nreally\u202e nice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e.Name != "alice" || e.Message != "Nice s***, really nice" {
+		t.Errorf("entry = %q by %q, want it sanitized", e.Message, e.Name)
+	}
+
+	// One entry per interval, and only a few a day
+	var limit *RateLimitError
+	if _, err := g.Sign("alice", "SHA256:a", "again"); !errors.As(err, &limit) || limit.Wait != guestbookInterval {
+		t.Errorf("signing again at once: err = %v, want a wait of %s", err, guestbookInterval)
+	}
+	if _, err := g.Sign("bob", "SHA256:b", "hello"); err != nil {
+		t.Errorf("another key: %v", err)
+	}
+	for i := 1; i < guestbookDaily; i++ {
+		now = now.Add(guestbookInterval)
+		if _, err := g.Sign("alice", "SHA256:a", "more"); err != nil {
+			t.Fatalf("entry %d: %v", i+1, err)
+		}
+	}
+	now = now.Add(guestbookInterval)
+	if _, err := g.Sign("alice", "SHA256:a", "too many"); !errors.As(err, &limit) {
+		t.Errorf("entry over the daily limit: err = %v, want a rate limit", err)
+	}
+
+	// Moderation, and the entries survive reopening
+	if err := g.SetHidden(e.ID, true); err != nil {
+		t.Fatal(err)
+	}
+	g, err = OpenGuestbook(guestbookFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	g.now = func() time.Time { return now }
+	if all, shown := g.Entries(10, true), g.Entries(10, false); len(all) != 4 || len(shown) != 3 {
+		t.Errorf("after hiding one: %d entries, %d shown; want 4 and 3", len(all), len(shown))
+	}
+	if _, err := g.Sign("alice", "SHA256:a", "still too many"); !errors.As(err, &limit) {
+		t.Errorf("entry over the daily limit after reopening: err = %v, want a rate limit", err)
+	}
+	if err := g.Delete(99); !errors.Is(err, ErrNoEntry) {
+		t.Errorf("deleting a missing entry: err = %v, want ErrNoEntry", err)
+	}
+
+	// IDs aren't reused once the newest entry is deleted, even after
+	// reopening
+	last := g.Entries(1, true)[0]
+	if err := g.Delete(last.ID); err != nil {
+		t.Fatal(err)
+	}
+	g, err = OpenGuestbook(guestbookFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	g.now = func() time.Time { return now }
+	now = now.Add(24 * time.Hour)
+	if e, err := g.Sign("alice", "SHA256:c", "hi"); err != nil || e.ID != last.ID+1 {
+		t.Errorf("signing after a delete: ID %d, err = %v; want ID %d", e.ID, err, last.ID+1)
+	}
+}
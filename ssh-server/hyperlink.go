@@ -0,0 +1,80 @@
+package main
+
+import (
+	"net/url"
+	"strings"
+
+	"github.com/charmbracelet/ssh"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// Hyperlink wraps text in an OSC 8 hyperlink to target. Terminals that
+// support it (iTerm2, kitty, WezTerm, GNOME Terminal, xterm.js...) make the
+// text clickable; most others ignore the sequence and show the text.
+func Hyperlink(target, text string) string {
+	return ansi.SetHyperlink(target) + text + ansi.ResetHyperlink()
+}
+
+// HyperlinksFromEnv decides whether a terminal gets OSC 8 hyperlinks. They
+// are on unless the visitor turns them off with HYPERLINKS=0 or the terminal
+// is one that prints unknown sequences instead of ignoring them.
+func HyperlinksFromEnv(getenv func(string) string) bool {
+	if on, ok := parseSwitch(getenv("HYPERLINKS")); ok {
+		return on
+	}
+	term := strings.ToLower(getenv("TERM"))
+	switch {
+	case term == "" || term == "dumb" || term == "linux":
+		return false
+	case strings.HasPrefix(term, "vt"):
+		return false
+	}
+	return true
+}
+
+// SSHHyperlinks reports whether an SSH session gets OSC 8 hyperlinks, from
+// its TERM and an optional HYPERLINKS variable (ssh -o SendEnv=HYPERLINKS).
+func SSHHyperlinks(s ssh.Session) bool {
+	return HyperlinksFromEnv(func(key string) string {
+		if key == "TERM" {
+			pty, _, _ := s.Pty()
+			return pty.Term
+		}
+		return lookupEnv(s.Environ(), key)
+	})
+}
+
+// WebSocketHyperlinks reports whether a WebSocket client gets OSC 8
+// hyperlinks. xterm.js supports them, so they're on unless the client asks
+// otherwise with ?links=0.
+func WebSocketHyperlinks(query url.Values) bool {
+	if on, ok := parseSwitch(query.Get("links")); ok {
+		return on
+	}
+	return true
+}
+
+// replaceText replaces old with new in the text of s, leaving escape
+// sequences alone, so the target of an OSC 8 hyperlink is not rewritten
+// along with the text it shows.
+func replaceText(s, old, new string) string {
+	if old == "" || !strings.Contains(s, old) {
+		return s
+	}
+	var sb strings.Builder
+	for i := 0; i < len(s); {
+		switch {
+		case s[i] == ansi.ESC:
+			_, _, n, _ := ansi.DecodeSequence(s[i:], ansi.NormalState, nil)
+			sb.WriteString(s[i : i+n])
+			i += n
+		case strings.HasPrefix(s[i:], old):
+			sb.WriteString(new)
+			i += len(old)
+		default:
+			sb.WriteByte(s[i])
+			i++
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,201 @@
+package main
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/charmbracelet/ssh"
+)
+
+// localesFS holds one message catalog per locale. Keys missing from a
+// translation fall back to English.
+//
+//go:embed locales
+var localesFS embed.FS
+
+// Locale is a supported UI language, identified by its ISO 639-1 code.
+type Locale string
+
+const (
+	LocaleEnglish Locale = "en"
+	LocaleSpanish Locale = "es"
+	LocaleCatalan Locale = "ca"
+)
+
+// DefaultLocale is used when a client doesn't ask for a supported language.
+const DefaultLocale = LocaleEnglish
+
+// Locales lists the supported locales in the order they are offered.
+var Locales = []Locale{LocaleEnglish, LocaleSpanish, LocaleCatalan}
+
+// ParseLocale parses a POSIX locale (es_ES.UTF-8, ca_ES@valencia) or a
+// language tag (es-ES, ca) into a supported locale.
+func ParseLocale(name string) (Locale, bool) {
+	name, _, _ = strings.Cut(name, ".")
+	name, _, _ = strings.Cut(name, "@")
+	lang, _, _ := strings.Cut(strings.ReplaceAll(name, "-", "_"), "_")
+	lang = strings.ToLower(strings.TrimSpace(lang))
+	for _, l := range Locales {
+		if string(l) == lang {
+			return l, true
+		}
+	}
+	return DefaultLocale, false
+}
+
+// LocaleFromEnv picks the message locale from a POSIX environment: the first
+// of LC_ALL, LC_MESSAGES and LANG that is set decides, as it would for a
+// local program.
+func LocaleFromEnv(getenv func(string) string) Locale {
+	for _, key := range []string{"LC_ALL", "LC_MESSAGES", "LANG"} {
+		if v := getenv(key); v != "" {
+			l, _ := ParseLocale(v)
+			return l
+		}
+	}
+	return DefaultLocale
+}
+
+// SSHLocale picks the locale of an SSH session from the LANG and LC_*
+// variables the client sent. OpenSSH forwards them when SendEnv includes
+// them, which many distributions configure by default.
+func SSHLocale(s ssh.Session) Locale {
+	return LocaleFromEnv(func(key string) string {
+		return lookupEnv(s.Environ(), key)
+	})
+}
+
+// HTTPLocale picks the locale of a WebSocket or API request from ?lang=,
+// falling back to the browser's Accept-Language header.
+func HTTPLocale(r *http.Request) Locale {
+	if l, ok := ParseLocale(r.URL.Query().Get("lang")); ok {
+		return l
+	}
+	for _, tag := range strings.Split(r.Header.Get("Accept-Language"), ",") {
+		tag, _, _ = strings.Cut(tag, ";")
+		if l, ok := ParseLocale(tag); ok {
+			return l
+		}
+	}
+	return DefaultLocale
+}
+
+// Catalog holds the UI messages of one locale.
+type Catalog struct {
+	Locale   Locale
+	messages map[string]string
+	fallback *Catalog
+}
+
+var catalogs = loadCatalogs()
+
+// loadCatalogs parses the embedded catalogs. They ship with the binary, so a
+// malformed one is a programming error and stops the server at startup.
+func loadCatalogs() map[Locale]*Catalog {
+	all := make(map[Locale]*Catalog, len(Locales))
+	for _, l := range Locales {
+		name := "locales/" + string(l) + ".json"
+		data, err := localesFS.ReadFile(name)
+		if err != nil {
+			panic(err)
+		}
+		c := &Catalog{Locale: l}
+		if err := json.Unmarshal(data, &c.messages); err != nil {
+			panic(fmt.Errorf("%s: %w", name, err))
+		}
+		all[l] = c
+	}
+	for l, c := range all {
+		if l != DefaultLocale {
+			c.fallback = all[DefaultLocale]
+		}
+	}
+	return all
+}
+
+// CatalogFor returns the catalog of locale l, or the default catalog when l
+// is not supported.
+func CatalogFor(l Locale) *Catalog {
+	if c, ok := catalogs[l]; ok {
+		return c
+	}
+	return catalogs[DefaultLocale]
+}
+
+// T returns the message for key, formatted with args when given. Missing
+// translations fall back to English, and unknown keys to the key itself so
+// they stand out.
+func (c *Catalog) T(key string, args ...interface{}) string {
+	msg, ok := c.messages[key]
+	if !ok {
+		if c.fallback != nil {
+			return c.fallback.T(key, args...)
+		}
+		msg = key
+	}
+	if len(args) > 0 {
+		return fmt.Sprintf(msg, args...)
+	}
+	return msg
+}
+
+// Has reports whether key has a message in this catalog or its fallback.
+func (c *Catalog) Has(key string) bool {
+	if _, ok := c.messages[key]; ok {
+		return true
+	}
+	return c.fallback != nil && c.fallback.Has(key)
+}
+
+// Describe returns the translated description of cmd.
+func (c *Catalog) Describe(cmd Command) string {
+	if key := "cmd." + cmd.Name; c.Has(key) {
+		return c.T(key)
+	}
+	return cmd.Description
+}
+
+// CategoryTitle returns the translated title of cat.
+func (c *Catalog) CategoryTitle(cat Category) string {
+	if key := "category." + cat.ID; c.Has(key) {
+		return c.T(key)
+	}
+	return cat.Title
+}
+
+// langCommand lists the supported languages, or switches the session's
+// language when given a code (`lang es`).
+func langCommand(ctx *CommandContext) (string, error) {
+	st, t := ctx.Styles, ctx.Catalog
+	if len(ctx.Args) > 1 {
+		return "", &UsageError{t.T("lang.usage")}
+	}
+	if len(ctx.Args) == 1 {
+		l, ok := ParseLocale(ctx.Args[0])
+		if !ok {
+			return "", &UsageError{t.T("lang.unknown", ctx.Args[0])}
+		}
+		ctx.SetLocale(l)
+		t = ctx.Catalog
+	}
+
+	var sb strings.Builder
+
+	sb.WriteString(st.Header(strings.ToUpper(t.T("lang.heading"))))
+	sb.WriteString("\n\n")
+	for _, l := range Locales {
+		name := CatalogFor(l).T("locale.name")
+		if l == t.Locale {
+			sb.WriteString(st.Label(padRight(string(l), 4)) + name + t.T("list.current") + "\n")
+		} else {
+			sb.WriteString(st.Value(padRight(string(l), 4)) + name + "\n")
+		}
+	}
+	sb.WriteString("\n")
+	sb.WriteString(st.Dim(t.T("lang.switch")))
+
+	return sb.String(), nil
+}
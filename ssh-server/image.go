@@ -0,0 +1,331 @@
+package main
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"strings"
+	"sync"
+
+	"github.com/charmbracelet/ssh"
+	"github.com/muesli/termenv"
+)
+
+// ImageProtocol is how images are drawn in a terminal.
+type ImageProtocol string
+
+const (
+	// ImageBlocks draws two pixels per cell with the upper half block and
+	// truecolor (or the closest palette colors). Every color terminal shows
+	// it, and it lays out like text, so the TUI always uses it.
+	ImageBlocks ImageProtocol = "blocks"
+	// ImageSixel draws real pixels with DEC sixel graphics (foot, mlterm,
+	// WezTerm, xterm -ti vt340, xterm.js with its image addon).
+	ImageSixel ImageProtocol = "sixel"
+	// ImageKitty draws real pixels with the kitty graphics protocol (kitty,
+	// WezTerm, Ghostty).
+	ImageKitty ImageProtocol = "kitty"
+	// ImageNone leaves images out.
+	ImageNone ImageProtocol = "none"
+)
+
+// Cell size assumed when drawing real pixels. Terminals don't report it
+// over SSH; most fonts are close to 1:2.
+const (
+	cellPixelWidth  = 10
+	cellPixelHeight = 20
+)
+
+// ParseImageProtocol parses a protocol name as sent in GRAPHICS.
+func ParseImageProtocol(name string) (ImageProtocol, bool) {
+	switch p := ImageProtocol(strings.ToLower(strings.TrimSpace(name))); p {
+	case ImageBlocks, ImageSixel, ImageKitty, ImageNone:
+		return p, true
+	case "halfblocks", "ansi":
+		return ImageBlocks, true
+	}
+	return ImageBlocks, false
+}
+
+// ImageProtocolFromEnv picks the best protocol a terminal advertises:
+// GRAPHICS=kitty|sixel|blocks|none when the visitor sets it, or else what
+// TERM is known to support.
+func ImageProtocolFromEnv(getenv func(string) string) ImageProtocol {
+	if p, ok := ParseImageProtocol(getenv("GRAPHICS")); ok {
+		return p
+	}
+	term := strings.ToLower(getenv("TERM"))
+	switch {
+	case strings.HasPrefix(term, "xterm-kitty"), strings.HasPrefix(term, "xterm-ghostty"),
+		strings.HasPrefix(term, "wezterm"):
+		return ImageKitty
+	case strings.HasPrefix(term, "foot"), strings.HasPrefix(term, "mlterm"),
+		strings.HasPrefix(term, "contour"):
+		return ImageSixel
+	}
+	return ImageBlocks
+}
+
+// SSHImageProtocol picks the image protocol of an SSH session from its TERM
+// and an optional GRAPHICS variable (ssh -o SendEnv=GRAPHICS).
+func SSHImageProtocol(s ssh.Session) ImageProtocol {
+	pty, _, ok := s.Pty()
+	if !ok {
+		return ImageNone
+	}
+	return ImageProtocolFromEnv(func(key string) string {
+		if key == "TERM" {
+			return pty.Term
+		}
+		return lookupEnv(s.Environ(), key)
+	})
+}
+
+// Image is a picture that can be drawn in a terminal. Renderings are cached
+// per size, protocol and color profile, since every visitor of a given
+// terminal gets the same one.
+type Image struct {
+	src  image.Image
+	data []byte // the encoded PNG, sent as is to kitty
+
+	mu    sync.Mutex
+	cache map[imageKey]string
+}
+
+type imageKey struct {
+	cols     int
+	protocol ImageProtocol
+	profile  termenv.Profile
+}
+
+// LoadImage decodes a PNG.
+func LoadImage(data []byte) (*Image, error) {
+	src, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	return &Image{src: src, data: data, cache: map[imageKey]string{}}, nil
+}
+
+// Rows returns how many terminal rows the image takes when drawn cols
+// cells wide.
+func (img *Image) Rows(cols int) int {
+	b := img.src.Bounds()
+	// Cells are twice as tall as they are wide, so each row holds two
+	// square pixels
+	return max((cols*b.Dy()/b.Dx()+1)/2, 1)
+}
+
+// Render draws the image cols cells wide. Block output is text lines of
+// exactly cols cells; sixel and kitty output is an escape sequence followed
+// by enough newlines to move past the picture. Without colors there is
+// nothing to draw and the result is empty.
+func (img *Image) Render(cols int, protocol ImageProtocol, profile termenv.Profile) string {
+	if cols <= 0 || protocol == ImageNone || profile == termenv.Ascii {
+		return ""
+	}
+
+	key := imageKey{cols, protocol, profile}
+	img.mu.Lock()
+	defer img.mu.Unlock()
+	if out, ok := img.cache[key]; ok {
+		return out
+	}
+
+	rows := img.Rows(cols)
+	var out string
+	switch protocol {
+	case ImageSixel:
+		out = sixel(scaleImage(img.src, cols*cellPixelWidth, rows*cellPixelHeight)) + strings.Repeat("\n", rows)
+	case ImageKitty:
+		out = kitty(img.data, cols, rows) + strings.Repeat("\n", rows)
+	default:
+		out = halfBlocks(scaleImage(img.src, cols, rows*2), profile)
+	}
+	img.cache[key] = out
+	return out
+}
+
+// scaleImage resizes src to w×h, averaging the source pixels behind each
+// target pixel when shrinking and repeating them when enlarging. Colors are
+// weighted by alpha so transparent pixels don't darken the edges.
+func scaleImage(src image.Image, w, h int) *image.NRGBA {
+	b := src.Bounds()
+	sw, sh := b.Dx(), b.Dy()
+	dst := image.NewNRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		y0 := y * sh / h
+		y1 := max((y+1)*sh/h, y0+1)
+		for x := 0; x < w; x++ {
+			x0 := x * sw / w
+			x1 := max((x+1)*sw/w, x0+1)
+
+			var r, g, bl, a, n uint64
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					// RGBA returns alpha-premultiplied 16-bit values
+					pr, pg, pb, pa := src.At(b.Min.X+sx, b.Min.Y+sy).RGBA()
+					r, g, bl, a = r+uint64(pr), g+uint64(pg), bl+uint64(pb), a+uint64(pa)
+					n++
+				}
+			}
+			c := color.NRGBA{A: uint8(a / n >> 8)}
+			if a > 0 {
+				c.R = uint8(r * 0xffff / a >> 8)
+				c.G = uint8(g * 0xffff / a >> 8)
+				c.B = uint8(bl * 0xffff / a >> 8)
+			}
+			dst.SetNRGBA(x, y, c)
+		}
+	}
+	return dst
+}
+
+// opaque reports whether a pixel is solid enough to draw. Terminal cells
+// can't blend, so pixels are either drawn or left to the background.
+func opaque(c color.NRGBA) bool {
+	return c.A >= 0x80
+}
+
+// halfBlocks draws img two rows of pixels per line with "▀", the upper
+// pixel as foreground and the lower one as background.
+func halfBlocks(img *image.NRGBA, profile termenv.Profile) string {
+	b := img.Bounds()
+	hex := func(c color.NRGBA) string {
+		return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+	}
+
+	var sb strings.Builder
+	for y := 0; y < b.Dy(); y += 2 {
+		if y > 0 {
+			sb.WriteString("\n")
+		}
+		for x := 0; x < b.Dx(); x++ {
+			top := img.NRGBAAt(x, y)
+			bottom := color.NRGBA{}
+			if y+1 < b.Dy() {
+				bottom = img.NRGBAAt(x, y+1)
+			}
+			switch {
+			case opaque(top) && opaque(bottom):
+				sb.WriteString(termenv.String("▀").Foreground(profile.Color(hex(top))).Background(profile.Color(hex(bottom))).String())
+			case opaque(top):
+				sb.WriteString(termenv.String("▀").Foreground(profile.Color(hex(top))).String())
+			case opaque(bottom):
+				sb.WriteString(termenv.String("▄").Foreground(profile.Color(hex(bottom))).String())
+			default:
+				sb.WriteString(" ")
+			}
+		}
+	}
+	return sb.String()
+}
+
+// sixel encodes img as DEC sixel graphics, with colors reduced to the 216
+// color cube. Transparent pixels are left undrawn.
+func sixel(img *image.NRGBA) string {
+	b := img.Bounds()
+	w, h := b.Dx(), b.Dy()
+
+	// Palette index of every pixel, -1 for transparent ones
+	index := make([]int, w*h)
+	used := map[int]bool{}
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			c := img.NRGBAAt(x, y)
+			i := -1
+			if opaque(c) {
+				i = int(c.R)*6/256*36 + int(c.G)*6/256*6 + int(c.B)*6/256
+				used[i] = true
+			}
+			index[y*w+x] = i
+		}
+	}
+
+	var sb strings.Builder
+	// P2=1 keeps undrawn pixels transparent
+	sb.WriteString("\x1bP0;1;0q")
+	fmt.Fprintf(&sb, "\"1;1;%d;%d", w, h)
+	for i := 0; i < 216; i++ {
+		if used[i] {
+			fmt.Fprintf(&sb, "#%d;2;%d;%d;%d", i, i/36*20, i/6%6*20, i%6*20)
+		}
+	}
+
+	for band := 0; band < h; band += 6 {
+		first := true
+		for i := 0; i < 216; i++ {
+			if !used[i] {
+				continue
+			}
+			var line strings.Builder
+			drawn := false
+			run, last := 0, byte(0)
+			flush := func() {
+				switch {
+				case run > 3:
+					fmt.Fprintf(&line, "!%d%c", run, last)
+				case run > 0:
+					line.WriteString(strings.Repeat(string(last), run))
+				}
+			}
+			for x := 0; x < w; x++ {
+				var bits byte
+				for dy := 0; dy < 6 && band+dy < h; dy++ {
+					if index[(band+dy)*w+x] == i {
+						bits |= 1 << dy
+					}
+				}
+				ch := '?' + bits
+				if bits != 0 {
+					drawn = true
+				}
+				if ch == last {
+					run++
+					continue
+				}
+				flush()
+				run, last = 1, ch
+			}
+			if !drawn {
+				continue
+			}
+			flush()
+			if !first {
+				// Back to the start of the band for the next color
+				sb.WriteString("$")
+			}
+			first = false
+			fmt.Fprintf(&sb, "#%d%s", i, line.String())
+		}
+		sb.WriteString("-")
+	}
+	sb.WriteString("\x1b\\")
+	return sb.String()
+}
+
+// kitty sends a PNG with the kitty graphics protocol, scaled by the
+// terminal to cols×rows cells. The cursor stays put (C=1), so the caller
+// moves past the picture itself.
+func kitty(data []byte, cols, rows int) string {
+	const chunk = 4096
+	payload := base64.StdEncoding.EncodeToString(data)
+
+	var sb strings.Builder
+	for i := 0; i < len(payload); i += chunk {
+		end := min(i+chunk, len(payload))
+		more := 0
+		if end < len(payload) {
+			more = 1
+		}
+		if i == 0 {
+			fmt.Fprintf(&sb, "\x1b_Gf=100,a=T,C=1,c=%d,r=%d,q=2,m=%d;%s\x1b\\", cols, rows, more, payload[i:end])
+		} else {
+			fmt.Fprintf(&sb, "\x1b_Gm=%d;%s\x1b\\", more, payload[i:end])
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// The intro plays when the TUI opens: a boot log typed out line by line,
+// then the banner revealed left to right, then the menu. Any key skips it.
+const (
+	introFrameRate    = 40 * time.Millisecond // time between frames
+	introTypeSpeed    = 4                     // boot log characters typed per frame
+	introLinePause    = 3                     // frames waited after each boot log line
+	introRevealFrames = 12                    // frames taken to reveal the banner
+	introHoldFrames   = 15                    // frames the finished banner stays before the menu
+)
+
+// introTickMsg advances the intro by one frame.
+type introTickMsg struct{}
+
+// introTick schedules the next frame of the intro.
+func introTick() tea.Cmd {
+	return tea.Tick(introFrameRate, func(time.Time) tea.Msg {
+		return introTickMsg{}
+	})
+}
+
+// WithIntro makes the model open with the intro. Accessible models go
+// straight to the menu, since a screen reader would read out every frame.
+func (m Model) WithIntro() Model {
+	if !m.styles.Accessible {
+		m.mode = IntroMode
+		m.frame = 0
+	}
+	return m
+}
+
+// updateIntro advances the intro a frame, and moves on to the menu after
+// the last one. Ticks still on their way after a skip are dropped.
+func (m Model) updateIntro() (tea.Model, tea.Cmd) {
+	if m.mode != IntroMode {
+		return m, nil
+	}
+	m.frame++
+	if m.frame >= introTypingFrames(m.bootLog())+introRevealFrames+introHoldFrames {
+		m.mode = MenuMode
+		return m, nil
+	}
+	return m, introTick()
+}
+
+// bootLog returns the lines typed out before the banner.
+func (m Model) bootLog() []string {
+	return strings.Split(m.catalog.T("intro.boot"), "\n")
+}
+
+// introTypingFrames is how many frames typing lines takes, pauses included.
+func introTypingFrames(lines []string) int {
+	frames := 0
+	for _, line := range lines {
+		frames += (len([]rune(line))+introTypeSpeed-1)/introTypeSpeed + introLinePause
+	}
+	return frames
+}
+
+// renderIntro renders the current frame of the intro.
+func (m Model) renderIntro() string {
+	lines := m.bootLog()
+	typing := introTypingFrames(lines)
+	if m.frame < typing {
+		return m.renderBootLog(lines)
+	}
+
+	// Reveal the banner a few columns per frame, then add the tagline
+	revealed := m.frame - typing + 1
+	if revealed >= introRevealFrames {
+		return m.renderWelcome()
+	}
+	width := len([]rune(banner[0])) * revealed / introRevealFrames
+	var sb strings.Builder
+	for _, line := range banner {
+		runes := []rune(line)
+		sb.WriteString(m.styles.Banner.Render(string(runes[:min(width, len(runes))])) + "\n")
+	}
+	return sb.String()
+}
+
+// renderBootLog renders the boot log typed up to the current frame, with a
+// cursor after the last character.
+func (m Model) renderBootLog(lines []string) string {
+	var sb strings.Builder
+	frames := m.frame
+	for _, line := range lines {
+		runes := []rune(line)
+		need := (len(runes) + introTypeSpeed - 1) / introTypeSpeed
+		if frames < need {
+			sb.WriteString(m.styles.Dim(string(runes[:frames*introTypeSpeed])) + "█\n")
+			break
+		}
+		sb.WriteString(m.styles.Dim(line) + "\n")
+		frames -= need + introLinePause
+		if frames < 0 {
+			sb.WriteString("█\n")
+			break
+		}
+	}
+	sb.WriteString("\n" + m.styles.Help.Render(m.catalog.T("intro.skip")))
+	return sb.String()
+}
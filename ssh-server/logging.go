@@ -0,0 +1,267 @@
+package main
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/charmbracelet/log"
+	"github.com/charmbracelet/ssh"
+	"github.com/charmbracelet/wish"
+)
+
+// LogConfig controls the output format, levels, redaction and sampling of
+// server logs. It is read from the environment so each deployment can tune
+// verbosity without a rebuild:
+//
+//	LOG_FORMAT=text|json|logfmt
+//	LOG_LEVEL=debug|info|warn|error
+//	LOG_LEVELS=ws=debug,ssh=warn   (per-component overrides)
+//	LOG_SAMPLE=100                 (log 1 in N high-volume events, 1 = all)
+//	LOG_REDACT_INPUT=false         (log typed input verbatim, for local debugging)
+type LogConfig struct {
+	Format      log.Formatter
+	Level       log.Level
+	Components  map[string]log.Level
+	SampleEvery uint64
+	RedactInput bool
+}
+
+// DefaultLogConfig returns the configuration used when no environment
+// overrides are present.
+func DefaultLogConfig() LogConfig {
+	return LogConfig{
+		Format:      log.TextFormatter,
+		Level:       log.InfoLevel,
+		Components:  map[string]log.Level{},
+		SampleEvery: 100,
+		RedactInput: true,
+	}
+}
+
+// LogConfigFromEnv builds a LogConfig from LOG_* environment variables.
+// Invalid values are reported and ignored rather than aborting startup.
+func LogConfigFromEnv() (LogConfig, []error) {
+	cfg := DefaultLogConfig()
+	var errs []error
+
+	if v := os.Getenv("LOG_FORMAT"); v != "" {
+		switch strings.ToLower(v) {
+		case "text":
+			cfg.Format = log.TextFormatter
+		case "json":
+			cfg.Format = log.JSONFormatter
+		case "logfmt":
+			cfg.Format = log.LogfmtFormatter
+		default:
+			errs = append(errs, fmt.Errorf("LOG_FORMAT: unknown format %q", v))
+		}
+	}
+
+	if v := os.Getenv("LOG_LEVEL"); v != "" {
+		level, err := log.ParseLevel(v)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("LOG_LEVEL: %w", err))
+		} else {
+			cfg.Level = level
+		}
+	}
+
+	if v := os.Getenv("LOG_LEVELS"); v != "" {
+		for _, pair := range strings.Split(v, ",") {
+			name, lvl, ok := strings.Cut(strings.TrimSpace(pair), "=")
+			if !ok || name == "" {
+				errs = append(errs, fmt.Errorf("LOG_LEVELS: malformed entry %q", pair))
+				continue
+			}
+			level, err := log.ParseLevel(lvl)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("LOG_LEVELS: %s: %w", name, err))
+				continue
+			}
+			cfg.Components[name] = level
+		}
+	}
+
+	if v := os.Getenv("LOG_SAMPLE"); v != "" {
+		n, err := strconv.ParseUint(v, 10, 64)
+		if err != nil || n == 0 {
+			errs = append(errs, fmt.Errorf("LOG_SAMPLE: expected a positive integer, got %q", v))
+		} else {
+			cfg.SampleEvery = n
+		}
+	}
+
+	if v := os.Getenv("LOG_REDACT_INPUT"); v != "" {
+		redact, err := strconv.ParseBool(v)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("LOG_REDACT_INPUT: %w", err))
+		} else {
+			cfg.RedactInput = redact
+		}
+	}
+
+	return cfg, errs
+}
+
+// Logging hands out component loggers that share one output and format but
+// may each run at their own level.
+type Logging struct {
+	root   *log.Logger
+	config LogConfig
+}
+
+// NewLogging creates the logging subsystem writing to w.
+func NewLogging(w io.Writer, cfg LogConfig) *Logging {
+	root := log.NewWithOptions(w, log.Options{
+		ReportTimestamp: true,
+		TimeFormat:      time.RFC3339,
+		Level:           cfg.Level,
+		Formatter:       cfg.Format,
+	})
+	return &Logging{root: root, config: cfg}
+}
+
+// Component returns a logger tagged with the component name and set to the
+// component's configured level, falling back to the global level.
+func (l *Logging) Component(name string) *log.Logger {
+	logger := l.root.With("component", name)
+	if level, ok := l.config.Components[name]; ok {
+		logger.SetLevel(level)
+	}
+	return logger
+}
+
+// Sampler returns a sampler for high-volume events using the configured rate.
+func (l *Logging) Sampler() *Sampler {
+	return NewSampler(l.config.SampleEvery)
+}
+
+// Input describes raw terminal input for logging. Named keys are always
+// reported; anything the visitor typed is redacted unless disabled.
+func (l *Logging) Input(data []byte) string {
+	if name, ok := keyNames[string(data)]; ok {
+		return name
+	}
+	if l.config.RedactInput {
+		return fmt.Sprintf("<redacted %d bytes>", len(data))
+	}
+	return fmt.Sprintf("%q", data)
+}
+
+// Command describes an exec request's command line for logging. The
+// command name is always reported; its arguments, which can hold what the
+// visitor typed into a form, are redacted like input unless disabled.
+func (l *Logging) Command(args []string) (name, rest string) {
+	if len(args) == 0 {
+		return "", ""
+	}
+	if len(args) == 1 {
+		return args[0], ""
+	}
+	if l.config.RedactInput {
+		return args[0], fmt.Sprintf("<redacted %d args>", len(args)-1)
+	}
+	return args[0], fmt.Sprintf("%q", args[1:])
+}
+
+// keyNames maps control sequences that are safe to log to readable names.
+var keyNames = map[string]string{
+	"\x1b[A": "up",
+	"\x1b[B": "down",
+	"\x1b[C": "right",
+	"\x1b[D": "left",
+	"\r":     "enter",
+	"\n":     "enter",
+	"\x1b":   "esc",
+	"\x7f":   "backspace",
+	"\b":     "backspace",
+	"\t":     "tab",
+	"\x03":   "ctrl+c",
+	"\x13":   "ctrl+s",
+}
+
+// Sampler lets one in every n events through so that per-keystroke and
+// per-frame logs don't drown everything else.
+type Sampler struct {
+	every uint64
+	count atomic.Uint64
+}
+
+// NewSampler creates a sampler that allows one in every n events. An n of
+// zero or one allows every event.
+func NewSampler(n uint64) *Sampler {
+	if n == 0 {
+		n = 1
+	}
+	return &Sampler{every: n}
+}
+
+// Allow reports whether the current event should be logged. The first event
+// is always allowed.
+func (s *Sampler) Allow() bool {
+	return (s.count.Add(1)-1)%s.every == 0
+}
+
+// Every returns the sampling rate, for inclusion in sampled log lines.
+func (s *Sampler) Every() uint64 {
+	return s.every
+}
+
+// newSessionID returns a short random identifier used to correlate every log
+// line belonging to one visitor, whether they arrived over SSH or WebSocket.
+func newSessionID() string {
+	b := make([]byte, 6)
+	if _, err := rand.Read(b); err != nil {
+		return strconv.FormatInt(time.Now().UnixNano(), 36)
+	}
+	return hex.EncodeToString(b)
+}
+
+type sessionIDContextKey struct{}
+
+// SessionID returns the correlation ID assigned to an SSH session by
+// sessionLoggingMiddleware.
+func SessionID(s ssh.Session) string {
+	id, _ := s.Context().Value(sessionIDContextKey{}).(string)
+	return id
+}
+
+// sessionLoggingMiddleware assigns every SSH session a correlation ID and
+// logs connects and disconnects with it.
+func sessionLoggingMiddleware(logger *log.Logger, logs *Logging) wish.Middleware {
+	return func(next ssh.Handler) ssh.Handler {
+		return func(s ssh.Session) {
+			id := newSessionID()
+			s.Context().SetValue(sessionIDContextKey{}, id)
+
+			start := time.Now()
+			pty, _, _ := s.Pty()
+			command, args := logs.Command(s.Command())
+			logger.Info("connect",
+				"session", id,
+				"user", s.User(),
+				"remote", s.RemoteAddr().String(),
+				"publicKey", s.PublicKey() != nil,
+				"command", command,
+				"args", args,
+				"term", pty.Term,
+				"width", pty.Window.Width,
+				"height", pty.Window.Height,
+				"clientVersion", s.Context().ClientVersion(),
+			)
+			next(s)
+			logger.Info("disconnect",
+				"session", id,
+				"remote", s.RemoteAddr().String(),
+				"duration", time.Since(start),
+			)
+		}
+	}
+}
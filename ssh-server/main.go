@@ -8,6 +8,7 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"sync"
 	"syscall"
 	"time"
 
@@ -16,23 +17,49 @@ import (
 	"github.com/charmbracelet/ssh"
 	"github.com/charmbracelet/wish"
 	"github.com/charmbracelet/wish/bubbletea"
-	"github.com/charmbracelet/wish/logging"
+	"github.com/muesli/termenv"
 )
 
 const (
-	host     = "0.0.0.0"
-	port     = "23234"
-	wsPort   = "8080" // WebSocket HTTP server port
+	host   = "0.0.0.0"
+	port   = "23234"
+	wsPort = "8080" // WebSocket HTTP server port
+
+	// shutdownTimeout bounds how long visitors get to finish after the
+	// farewell notice before their sessions are closed forcibly.
+	shutdownTimeout = 20 * time.Second
 )
 
 func main() {
-	// Setup logger with Info level to see all messages
-	logger := log.NewWithOptions(os.Stderr, log.Options{
-		ReportTimestamp: true,
-		TimeFormat:      time.Kitchen,
-		Prefix:          "Genar.me SSH 🚀",
-		Level:           log.DebugLevel, // Show debug messages too
-	})
+	// Setup logging from LOG_* environment variables
+	logCfg, logErrs := LogConfigFromEnv()
+	logs := NewLogging(os.Stderr, logCfg)
+	logger := logs.Component("main")
+	for _, err := range logErrs {
+		logger.Warn("Ignoring invalid logging configuration", "error", err)
+	}
+	sshLogger := logs.Component("ssh")
+	registry := NewSessionRegistry()
+
+	// Record sessions as configured by RECORD_* environment variables
+	recordCfg, recordErrs := RecordConfigFromEnv()
+	recordings := NewRecordings(recordCfg, logs.Component("record"))
+	for _, err := range recordErrs {
+		logger.Warn("Ignoring invalid recording configuration", "error", err)
+	}
+	if err := recordings.Prune(); err != nil {
+		logger.Warn("Failed to prune recordings", "error", err)
+	}
+
+	// Deliver contact messages in the background until shutdown
+	deliverCtx, stopDelivery := context.WithCancel(context.Background())
+	defer stopDelivery()
+	if outbox, err := LoadOutbox(); err != nil {
+		logger.Warn("Contact messages are unavailable", "error", err)
+	} else {
+		contactLogger := logs.Component("contact")
+		go outbox.Deliver(deliverCtx, ContactDelivererFromEnv(contactLogger), contactLogger)
+	}
 
 	// Create SSH server
 	s, err := wish.NewServer(
@@ -49,8 +76,10 @@ func main() {
 			return true
 		}),
 		wish.WithMiddleware(
-			bubbletea.Middleware(teaHandler),
-			logging.Middleware(),
+			bubbletea.MiddlewareWithProgramHandler(teaHandler(sshLogger, recordings), termenv.Ascii),
+			execMiddleware(sshLogger),
+			sessionRegistryMiddleware(registry),
+			sessionLoggingMiddleware(sshLogger, logs),
 		),
 	)
 	if err != nil {
@@ -67,22 +96,26 @@ func main() {
 
 	// Start HTTP server for WebSocket connections
 	mux := http.NewServeMux()
-	
+
 	// Add logging middleware
+	httpLogger := logs.Component("http")
+	wsHandler := WebSocketHandler(logs, registry, recordings)
 	loggingHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		logger.Info("HTTP request received", "method", r.Method, "path", r.URL.Path, "remote", r.RemoteAddr)
-		WebSocketHandler(logger)(w, r)
+		httpLogger.Info("HTTP request received", "method", r.Method, "path", r.URL.Path, "remote", r.RemoteAddr)
+		wsHandler(w, r)
 	})
-	
+
 	mux.HandleFunc("/ws", loggingHandler)
-	
+	mux.HandleFunc("GET /api/{command}", APIHandler(httpLogger, registry))
+	mux.HandleFunc("GET /vcard.vcf", DownloadHandler(httpLogger, "genar.vcf", "text/vcard; charset=utf-8", vcardDocument))
+	mux.HandleFunc("GET /resume.json", DownloadHandler(httpLogger, "resume.json", "application/json; charset=utf-8", resumeDocument))
+
 	httpServer := &http.Server{
 		Addr:    net.JoinHostPort(host, wsPort),
 		Handler: mux,
 	}
 
-	logger.Info("*** Starting WebSocket server ***", "host", host, "port", wsPort, "endpoint", "/ws")
-	logger.Info("WebSocket handler registered", "fullURL", fmt.Sprintf("ws://%s:%s/ws", host, wsPort))
+	logger.Info("Starting WebSocket server", "host", host, "port", wsPort, "url", fmt.Sprintf("ws://%s:%s/ws", host, wsPort))
 
 	go func() {
 		if err = s.ListenAndServe(); err != nil && !errors.Is(err, ssh.ErrServerClosed) {
@@ -99,42 +132,106 @@ func main() {
 	}()
 
 	<-done
-	logger.Info("Stopping servers")
-	
-	// Shutdown SSH server
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	logger.Info("Stopping servers", "sessions", registry.Len(), "timeout", shutdownTimeout)
+
+	// Stop accepting connections on both servers and drain open sessions
+	// concurrently, all bounded by the same deadline.
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
 	defer cancel()
-	if err := s.Shutdown(ctx); err != nil && !errors.Is(err, ssh.ErrServerClosed) {
-		logger.Error("Failed to shutdown SSH server", "error", err)
-	}
-	
-	// Shutdown HTTP server
-	ctx2, cancel2 := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel2()
-	if err := httpServer.Shutdown(ctx2); err != nil {
-		logger.Error("Failed to shutdown HTTP server", "error", err)
-	}
+
+	var wg sync.WaitGroup
+	wg.Add(3)
+	go func() {
+		defer wg.Done()
+		if err := s.Shutdown(ctx); err != nil && !errors.Is(err, ssh.ErrServerClosed) {
+			logger.Warn("SSH server did not shut down gracefully, closing", "error", err)
+			_ = s.Close()
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		if err := httpServer.Shutdown(ctx); err != nil {
+			logger.Warn("HTTP server did not shut down gracefully, closing", "error", err)
+			_ = httpServer.Close()
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		if err := registry.Drain(ctx, shutdownNotice); err != nil {
+			logger.Warn("Sessions still open at deadline were closed", "error", err)
+		}
+	}()
+	wg.Wait()
+
+	logger.Info("Servers stopped")
 }
 
 // teaHandler creates a new Bubble Tea program for each SSH session
-func teaHandler(s ssh.Session) (tea.Model, []tea.ProgramOption) {
-	// Get terminal size
-	pty, _, active := s.Pty()
-	if !active {
-		fmt.Fprintln(s, "No active terminal, skipping")
-		return nil, nil
-	}
+func teaHandler(logger *log.Logger, recordings *Recordings) bubbletea.ProgramHandler {
+	return func(s ssh.Session) *tea.Program {
+		// Get terminal size
+		pty, _, active := s.Pty()
+		if !active {
+			logger.Debug("No active terminal, skipping", "session", SessionID(s))
+			fmt.Fprintln(s, "No active terminal, skipping")
+			return nil
+		}
+
+		// Create new model for this session
+		m := NewModel()
+		m.width = pty.Window.Width
+		m.height = pty.Window.Height
+		m.user = s.User()
+		m.key = SSHKeyFingerprint(s)
+		m.session = sessionFromContext(s)
+
+		// Render with the client's color profile rather than the server's
+		profile := SSHColorProfile(s)
+		m.styles = NewStyles(MustTheme(DefaultTheme), NewSessionRenderer(s, profile)).WithHyperlinks(SSHHyperlinks(s))
+		accessible := SSHAccessible(s)
+		if accessible {
+			m.styles = m.styles.WithAccessible(true)
+		}
+		m.catalog = CatalogFor(SSHLocale(s))
+		m.clipboard = OSC52Clipboard(s, pty.Term)
+		logger.Debug("Starting TUI", "session", SessionID(s), "term", pty.Term, "colors", ColorProfileName(profile),
+			"accessible", accessible, "locale", m.catalog.Locale)
+
+		// Configure Bubble Tea program options
+		opts := []tea.ProgramOption{
+			// Process signals belong to main, which drains sessions itself
+			tea.WithoutSignalHandler(),
+		}
+		if !accessible {
+			// Screen readers lose the scrollback in the alternate screen,
+			// and mouse reporting gets in the way of selecting text.
+			// All-motion tracking reports the pointer without a button
+			// held, for hover highlighting.
+			opts = append(opts, tea.WithAltScreen(), tea.WithMouseAllMotion())
+		}
+		opts = append(opts, bubbletea.MakeOptions(s)...)
+		if recordings.Wanted(SSHRecord(s)) {
+			rec, err := recordings.Start(SessionID(s), TransportSSH, pty.Window.Width, pty.Window.Height, pty.Term)
+			if err != nil {
+				logger.Error("Failed to start recording", "session", SessionID(s), "error", err)
+			} else {
+				// Sessions use an emulated pty, so the program writes to s
+				opts = append(opts, tea.WithOutput(rec.Writer(s)), tea.WithFilter(rec.Filter))
+				m.recording = true
+				go func() {
+					<-s.Context().Done()
+					if err := rec.Close(); err != nil {
+						logger.Error("Failed to save recording", "session", SessionID(s), "error", err)
+					}
+				}()
+			}
+		}
 
-	// Create new model for this session
-	m := NewModel()
-	m.width = pty.Window.Width
-	m.height = pty.Window.Height
+		p := tea.NewProgram(m.WithIntro(), opts...)
+		if sess := sessionFromContext(s); sess != nil {
+			sess.SetSender(p.Send)
+		}
 
-	// Configure Bubble Tea program options
-	opts := []tea.ProgramOption{
-		tea.WithAltScreen(),       // Use alternate screen buffer
-		tea.WithMouseCellMotion(), // Enable mouse support
+		return p
 	}
-
-	return m, opts
 }
@@ -0,0 +1,608 @@
+package main
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// RenderMarkdown renders Markdown source for the terminal with st, wrapping
+// text to width cells (0 for no wrapping). Without styles, or in accessible
+// mode, the result is plain text: markup is dropped, links are followed by
+// their URL and code is indented.
+//
+// Content files use a subset of CommonMark: ATX headings, paragraphs,
+// bullet and numbered lists nested by indentation, block quotes, fenced
+// code blocks, thematic breaks, and inline emphasis, code spans, links and
+// autolinks.
+func RenderMarkdown(src string, st *Styles, width int) string {
+	if st == nil {
+		return renderMarkdownIn(src, nil, width, lipgloss.Style{})
+	}
+	return renderMarkdownIn(src, st, width, st.Content.UnsetPadding())
+}
+
+// renderMarkdownIn is RenderMarkdown with text in base rather than the
+// content style, for prose set apart like the interests in `about`.
+func renderMarkdownIn(src string, st *Styles, width int, base lipgloss.Style) string {
+	r := markdownRenderer{st: st, plain: st == nil || st.Accessible, base: base}
+	return r.render(src, width)
+}
+
+// markdownWidth is how wide to wrap Markdown on a terminal width cells
+// wide: the terminal's width, up to a comfortable line length.
+func markdownWidth(width int) int {
+	if width <= 0 {
+		return 80
+	}
+	return min(width, 80)
+}
+
+// markdownRenderer renders with one base style for plain text, which block
+// quotes change for what they hold.
+type markdownRenderer struct {
+	st    *Styles
+	plain bool
+	base  lipgloss.Style
+}
+
+type mdKind int
+
+const (
+	mdParagraph mdKind = iota
+	mdHeading
+	mdItem
+	mdQuote
+	mdCode
+	mdRule
+)
+
+// mdBlock is a block of the document. List items are blocks of their own,
+// told apart from their neighbours by depth.
+type mdBlock struct {
+	kind   mdKind
+	level  int    // heading level, or list nesting depth
+	marker string // list item marker: "-" for bullets, "1." for numbers
+	lang   string // code block language
+	text   string // inline text, or the Markdown inside a quote
+	lines  []string
+}
+
+var (
+	mdHeadingLine = regexp.MustCompile(`^(#{1,6})(?:\s+(.*?))?\s*#*\s*$`)
+	mdItemLine    = regexp.MustCompile(`^([ \t]*)([-*+]|\d{1,9}[.)])[ \t]+(.*)$`)
+	mdRuleLine    = regexp.MustCompile(`^(?:(?:\*[ \t]*){3,}|(?:-[ \t]*){3,}|(?:_[ \t]*){3,})$`)
+)
+
+// parseMarkdown splits src into blocks.
+func parseMarkdown(src string) []mdBlock {
+	var blocks []mdBlock
+	lines := strings.Split(strings.ReplaceAll(src, "\r\n", "\n"), "\n")
+	open := false // the last block takes lazy continuation lines
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		trimmed := strings.TrimSpace(line)
+
+		switch {
+		case trimmed == "":
+			open = false
+
+		case strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~"):
+			fence := trimmed[:3]
+			b := mdBlock{kind: mdCode, lang: strings.ToLower(strings.Trim(trimmed, "`~ \t"))}
+			for i++; i < len(lines) && !strings.HasPrefix(strings.TrimSpace(lines[i]), fence); i++ {
+				b.lines = append(b.lines, strings.TrimRight(lines[i], " \t"))
+			}
+			blocks = append(blocks, b)
+			open = false
+
+		case mdHeadingLine.MatchString(trimmed):
+			m := mdHeadingLine.FindStringSubmatch(trimmed)
+			blocks = append(blocks, mdBlock{kind: mdHeading, level: len(m[1]), text: m[2]})
+			open = false
+
+		case mdRuleLine.MatchString(trimmed):
+			blocks = append(blocks, mdBlock{kind: mdRule})
+			open = false
+
+		case strings.HasPrefix(trimmed, ">"):
+			var quoted []string
+			for ; i < len(lines) && strings.HasPrefix(strings.TrimSpace(lines[i]), ">"); i++ {
+				q := strings.TrimPrefix(strings.TrimSpace(lines[i]), ">")
+				quoted = append(quoted, strings.TrimPrefix(q, " "))
+			}
+			i--
+			blocks = append(blocks, mdBlock{kind: mdQuote, text: strings.Join(quoted, "\n")})
+			open = false
+
+		case mdItemLine.MatchString(line):
+			m := mdItemLine.FindStringSubmatch(line)
+			indent := len(strings.ReplaceAll(m[1], "\t", "    "))
+			marker := m[2]
+			if !unicode.IsDigit(rune(marker[0])) {
+				marker = "-"
+			}
+			blocks = append(blocks, mdBlock{kind: mdItem, level: indent / 2, marker: marker, text: m[3]})
+			open = true
+
+		case open:
+			last := &blocks[len(blocks)-1]
+			last.text += " " + trimmed
+
+		default:
+			blocks = append(blocks, mdBlock{kind: mdParagraph, text: trimmed})
+			open = true
+		}
+	}
+	return blocks
+}
+
+// render renders src wrapped to width.
+func (r markdownRenderer) render(src string, width int) string {
+	var sb strings.Builder
+	blocks := parseMarkdown(src)
+	for i, b := range blocks {
+		if i > 0 {
+			// Items of one list stay together
+			if b.kind == mdItem && blocks[i-1].kind == mdItem {
+				sb.WriteString("\n")
+			} else {
+				sb.WriteString("\n\n")
+			}
+		}
+		sb.WriteString(r.renderBlock(b, width))
+	}
+	return sb.String()
+}
+
+// renderBlock renders one block, without a trailing newline.
+func (r markdownRenderer) renderBlock(b mdBlock, width int) string {
+	switch b.kind {
+	case mdHeading:
+		if r.plain {
+			return r.wrap(parseInline(b.text), width, "", "", r.base)
+		}
+		heading := r.wrap(parseInline(b.text), width, "", "", r.st.Heading)
+		if b.level == 1 {
+			// Underline top-level headings
+			heading += "\n" + r.st.Heading.Render(strings.Repeat("─", lipgloss.Width(heading)))
+		}
+		return heading
+
+	case mdItem:
+		indent := strings.Repeat("  ", b.level)
+		marker := b.marker
+		if marker == "-" && !r.plain {
+			marker = r.st.Bullet.Render("•")
+		} else if !r.plain {
+			marker = r.st.Bullet.Render(marker)
+		}
+		hang := indent + strings.Repeat(" ", lipgloss.Width(marker)+1)
+		return r.wrap(parseInline(b.text), width, indent+marker+" ", hang, r.base)
+
+	case mdQuote:
+		bar := "> "
+		inner := r
+		if !r.plain {
+			bar = r.st.Quote.Render("│") + " "
+			inner.base = r.st.Quote
+		}
+		lines := strings.Split(inner.render(b.text, max(width-2, 0)), "\n")
+		for i, line := range lines {
+			lines[i] = bar + line
+		}
+		return strings.Join(lines, "\n")
+
+	case mdCode:
+		lines := make([]string, len(b.lines))
+		for i, line := range b.lines {
+			if r.plain {
+				lines[i] = "    " + line
+				continue
+			}
+			line = "  " + r.highlight(line, b.lang)
+			if width > 0 {
+				line = ansi.Truncate(line, width, "…")
+			}
+			lines[i] = line
+		}
+		return strings.Join(lines, "\n")
+
+	case mdRule:
+		if r.plain {
+			return "---"
+		}
+		if width <= 0 {
+			width = 40
+		}
+		return r.st.Dim(strings.Repeat("─", width))
+	}
+	return r.wrap(parseInline(b.text), width, "", "", r.base)
+}
+
+// mdSpan is a run of inline text with the same formatting.
+type mdSpan struct {
+	text   string
+	strong bool
+	em     bool
+	code   bool
+	url    string // link target, "" outside links
+}
+
+// parseInline splits the inline Markdown s into spans. Delimiters without a
+// partner are kept as text, so "5 * 3" and snake_case survive.
+func parseInline(s string) []mdSpan {
+	return parseInlineIn(s, mdSpan{})
+}
+
+// parseInlineIn parses s inside the formatting of outer, for link text.
+func parseInlineIn(s string, outer mdSpan) []mdSpan {
+	var spans []mdSpan
+	cur := outer
+	var text strings.Builder
+	flush := func() {
+		if text.Len() > 0 {
+			sp := cur
+			sp.text = text.String()
+			spans = append(spans, sp)
+			text.Reset()
+		}
+	}
+
+	for i := 0; i < len(s); {
+		c := s[i]
+		switch {
+		case c == '\\' && i+1 < len(s) && strings.IndexByte("\\`*_[]()<>#+-.!|~", s[i+1]) >= 0:
+			text.WriteByte(s[i+1])
+			i += 2
+			continue
+
+		case c == '`':
+			n := countRun(s[i:], '`')
+			fence := s[i : i+n]
+			if end := strings.Index(s[i+n:], fence); end >= 0 {
+				flush()
+				code := strings.TrimSpace(s[i+n : i+n+end])
+				spans = append(spans, mdSpan{text: code, code: true, url: cur.url})
+				i += n + end + n
+				continue
+			}
+
+		case c == '*' || c == '_':
+			n := countRun(s[i:], c)
+			prev, _ := utf8.DecodeLastRuneInString(s[:i])
+			next, _ := utf8.DecodeRuneInString(s[i+n:])
+			opening := i+n < len(s) && !unicode.IsSpace(next)
+			closing := i > 0 && !unicode.IsSpace(prev)
+			if c == '_' {
+				// Underscores inside words are just underscores
+				opening = opening && (i == 0 || !isWordRune(prev))
+				closing = closing && (i+n == len(s) || !isWordRune(next))
+			}
+			delim := s[i : i+n]
+			var toggled bool
+			switch {
+			case closing && n >= 2 && cur.strong:
+				flush()
+				cur.strong = false
+				toggled = true
+			case closing && n == 1 && cur.em:
+				flush()
+				cur.em = false
+				toggled = true
+			case opening && strings.Contains(s[i+n:], delim):
+				flush()
+				if n >= 2 {
+					cur.strong = true
+				} else {
+					cur.em = true
+				}
+				toggled = true
+			}
+			if toggled {
+				i += min(n, 2)
+				continue
+			}
+			text.WriteString(delim)
+			i += n
+			continue
+
+		case c == '[':
+			// The label runs to the matching bracket, which the URL must
+			// follow at once; otherwise the bracket is plain text
+			if end := matchBracket(s[i:], '[', ']'); end > 0 && strings.HasPrefix(s[i+end+1:], "(") {
+				if close := matchBracket(s[i+end+1:], '(', ')'); close > 0 {
+					flush()
+					label := s[i+1 : i+end]
+					link := cur
+					link.url = strings.TrimSpace(s[i+end+2 : i+end+1+close])
+					spans = append(spans, parseInlineIn(label, link)...)
+					i += end + close + 2
+					continue
+				}
+			}
+
+		case c == '<':
+			if end := strings.IndexByte(s[i:], '>'); end > 0 {
+				target := s[i+1 : i+end]
+				if strings.Contains(target, "://") || strings.HasPrefix(target, "mailto:") {
+					flush()
+					link := cur
+					link.url = target
+					link.text = strings.TrimPrefix(target, "mailto:")
+					spans = append(spans, link)
+					i += end + 1
+					continue
+				}
+			}
+		}
+		text.WriteByte(c)
+		i++
+	}
+	flush()
+	return spans
+}
+
+// matchBracket returns the index of the close bracket matching the open
+// one s starts with, counting nested pairs, or -1 when there is none.
+func matchBracket(s string, open, close byte) int {
+	depth := 0
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case open:
+			depth++
+		case close:
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// countRun counts how many times c repeats at the start of s.
+func countRun(s string, c byte) int {
+	n := 0
+	for n < len(s) && s[n] == c {
+		n++
+	}
+	return n
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// mdWord is a word ready to lay out: its rendering, its width in cells and
+// whether a space separates it from the word before.
+type mdWord struct {
+	text  string
+	width int
+	space bool
+}
+
+// words renders spans word by word. Each word carries its own styling, so
+// lines can be broken, clipped or laid out side by side without styles
+// bleeding from one line into the next.
+func (r markdownRenderer) words(spans []mdSpan, base lipgloss.Style) []mdWord {
+	var words []mdWord
+	space := false
+	add := func(text string, sp mdSpan) {
+		out := text
+		if !r.plain {
+			style := base
+			switch {
+			case sp.code:
+				style = r.st.Code
+			case sp.url != "":
+				style = r.st.LinkText
+			}
+			if sp.strong {
+				style = style.Bold(true)
+			}
+			if sp.em {
+				style = style.Italic(true)
+			}
+			out = style.Render(text)
+			if sp.url != "" && r.st.Hyperlinks {
+				out = Hyperlink(markdownURL(sp.url), out)
+			}
+		}
+		words = append(words, mdWord{text: out, width: ansi.StringWidth(text), space: space})
+		space = false
+	}
+
+	for i, sp := range spans {
+		var word strings.Builder
+		for _, c := range sp.text {
+			if unicode.IsSpace(c) {
+				if word.Len() > 0 {
+					add(word.String(), sp)
+					word.Reset()
+				}
+				space = len(words) > 0
+				continue
+			}
+			word.WriteRune(c)
+		}
+		if word.Len() > 0 {
+			add(word.String(), sp)
+		}
+
+		// Show the target after link text when it can't be clicked
+		lastOfLink := sp.url != "" && (i+1 == len(spans) || spans[i+1].url != sp.url)
+		if lastOfLink && (r.plain || !r.st.Hyperlinks) && strings.TrimPrefix(sp.url, "mailto:") != linkText(spans, i) {
+			target := strings.TrimPrefix(sp.url, "mailto:")
+			if r.plain {
+				words = append(words, mdWord{text: "(" + target + ")", width: ansi.StringWidth(target) + 2, space: true})
+			} else {
+				words = append(words, mdWord{text: r.st.Dim(target), width: ansi.StringWidth(target), space: true})
+			}
+		}
+	}
+	return words
+}
+
+// linkText returns the text of the link whose last span is spans[last].
+func linkText(spans []mdSpan, last int) string {
+	first := last
+	for first > 0 && spans[first-1].url == spans[last].url {
+		first--
+	}
+	var sb strings.Builder
+	for _, sp := range spans[first : last+1] {
+		sb.WriteString(sp.text)
+	}
+	return strings.TrimSpace(sb.String())
+}
+
+// wrap lays out spans in lines of at most width cells, the first starting
+// with first and the rest with hang. Words only break at spaces; a word
+// longer than a line gets a line of its own.
+func (r markdownRenderer) wrap(spans []mdSpan, width int, first, hang string, base lipgloss.Style) string {
+	var sb strings.Builder
+	sb.WriteString(first)
+	col := ansi.StringWidth(first)
+	lineStart := true
+	for _, w := range r.words(spans, base) {
+		if w.space && !lineStart {
+			if width > 0 && col+1+w.width > width {
+				sb.WriteString("\n" + hang)
+				col = ansi.StringWidth(hang)
+			} else {
+				sb.WriteString(" ")
+				col++
+			}
+		}
+		sb.WriteString(w.text)
+		col += w.width
+		lineStart = false
+	}
+	return sb.String()
+}
+
+// Keywords highlighted in code blocks, by language.
+var codeKeywords = map[string][]string{
+	"go": {"break", "case", "chan", "const", "continue", "default", "defer", "else", "fallthrough",
+		"for", "func", "go", "goto", "if", "import", "interface", "map", "package", "range",
+		"return", "select", "struct", "switch", "type", "var", "nil", "true", "false"},
+	"js": {"async", "await", "break", "case", "catch", "class", "const", "continue", "default",
+		"else", "export", "extends", "for", "from", "function", "if", "import", "interface",
+		"let", "new", "null", "of", "return", "switch", "this", "throw", "try", "type",
+		"undefined", "var", "while", "true", "false"},
+	"python": {"and", "as", "async", "await", "class", "def", "elif", "else", "except", "for",
+		"from", "if", "import", "in", "is", "lambda", "None", "not", "or", "pass", "raise",
+		"return", "try", "while", "with", "yield", "True", "False"},
+	"sh": {"case", "do", "done", "echo", "elif", "else", "esac", "export", "fi", "for",
+		"function", "if", "in", "local", "then", "while"},
+	"rust": {"as", "async", "await", "enum", "fn", "for", "if", "impl", "let", "loop", "match",
+		"mod", "mut", "pub", "return", "self", "struct", "trait", "use", "where", "while",
+		"true", "false"},
+	"json": {"true", "false", "null"},
+}
+
+// codeLanguages maps the names code blocks are tagged with to the keyword
+// sets above.
+var codeLanguages = map[string]string{
+	"go": "go", "golang": "go",
+	"js": "js", "javascript": "js", "ts": "js", "typescript": "js", "jsx": "js", "tsx": "js",
+	"py": "python", "python": "python",
+	"sh": "sh", "bash": "sh", "shell": "sh", "zsh": "sh", "console": "sh",
+	"rs": "rust", "rust": "rust",
+	"json": "json", "yaml": "json", "yml": "json", "toml": "json",
+}
+
+// lineComment returns how comments start in lang, "" for unknown.
+func lineComment(lang string) string {
+	switch lang {
+	case "go", "js", "rust":
+		return "//"
+	case "python", "sh":
+		return "#"
+	case "yaml", "yml", "toml":
+		return "#"
+	}
+	return ""
+}
+
+// highlight colors a line of code: keywords, strings, numbers and line
+// comments. It works a line at a time, which is enough for the short
+// snippets in articles; block comments and multi-line strings stay plain.
+func (r markdownRenderer) highlight(line, lang string) string {
+	st := r.st
+	keywords := map[string]bool{}
+	family := codeLanguages[lang]
+	for _, k := range codeKeywords[family] {
+		keywords[k] = true
+	}
+	comment := lineComment(family)
+	if comment == "" {
+		comment = lineComment(lang)
+	}
+
+	var sb, plain strings.Builder
+	emit := func(style lipgloss.Style, s string) {
+		if plain.Len() > 0 {
+			sb.WriteString(st.Content.UnsetPadding().Render(plain.String()))
+			plain.Reset()
+		}
+		sb.WriteString(style.Render(s))
+	}
+	for i := 0; i < len(line); {
+		c := line[i]
+		switch {
+		case comment != "" && strings.HasPrefix(line[i:], comment):
+			emit(st.CodeComment, line[i:])
+			return sb.String()
+
+		case family != "" && (c == '"' || c == '\'' || c == '`'):
+			end := i + 1
+			for end < len(line) && line[end] != c {
+				if line[end] == '\\' {
+					end++
+				}
+				end++
+			}
+			end = min(end+1, len(line))
+			emit(st.CodeString, line[i:end])
+			i = end
+
+		case family != "" && c >= '0' && c <= '9' && (i == 0 || !isIdentByte(line[i-1])):
+			end := i
+			for end < len(line) && (isIdentByte(line[end]) || line[end] == '.') {
+				end++
+			}
+			emit(st.CodeNumber, line[i:end])
+			i = end
+
+		case isIdentByte(c):
+			end := i
+			for end < len(line) && isIdentByte(line[end]) {
+				end++
+			}
+			if word := line[i:end]; keywords[word] {
+				emit(st.CodeKeyword, word)
+			} else {
+				plain.WriteString(word)
+			}
+			i = end
+
+		default:
+			plain.WriteByte(c)
+			i++
+		}
+	}
+	if plain.Len() > 0 {
+		sb.WriteString(st.Content.UnsetPadding().Render(plain.String()))
+	}
+	return sb.String()
+}
+
+func isIdentByte(c byte) bool {
+	return c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9'
+}
@@ -0,0 +1,57 @@
+package main
+
+import "testing"
+
+func TestRenderMarkdownPlain(t *testing.T) {
+	tests := []struct {
+		name, src, want string
+		width           int
+	}{
+		{
+			name: "emphasis and code",
+			src:  "Some **bold**, *italic* and `code`.",
+			want: "Some bold, italic and code.",
+		},
+		{
+			name: "unpaired delimiters",
+			src:  "5 * 3 in snake_case_names",
+			want: "5 * 3 in snake_case_names",
+		},
+		{
+			name: "links",
+			src:  "See [my blog](https://yourblog.com) or <https://genar.me>.",
+			want: "See my blog (https://yourblog.com) or https://genar.me.",
+		},
+		{
+			name: "brackets that aren't links",
+			src:  "[a] b [c](d) and [x [y]](https://en.wikipedia.org/wiki/Go_(game)) [z] (w)",
+			want: "[a] b c (d) and x [y] (https://en.wikipedia.org/wiki/Go_(game)) [z] (w)",
+		},
+		{
+			name: "heading and list",
+			src:  "# Title\n\n- one\n  - nested\n1. first",
+			want: "Title\n\n- one\n  - nested\n1. first",
+		},
+		{
+			name: "lazy continuation",
+			src:  "A paragraph\nover two lines.\n\n- an item\ncontinued",
+			want: "A paragraph over two lines.\n\n- an item continued",
+		},
+		{
+			name: "code block and quote",
+			src:  "```go\nfunc main() {}\n```\n\n> quoted\n> text",
+			want: "    func main() {}\n\n> quoted text",
+		},
+		{
+			name:  "wrapping",
+			src:   "- one two three four",
+			want:  "- one two\n  three four",
+			width: 12,
+		},
+	}
+	for _, tt := range tests {
+		if got := RenderMarkdown(tt.src, nil, tt.width); got != tt.want {
+			t.Errorf("%s: RenderMarkdown(%q) = %q, want %q", tt.name, tt.src, got, tt.want)
+		}
+	}
+}
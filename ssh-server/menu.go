@@ -0,0 +1,259 @@
+package main
+
+// MenuItemKind tells the kinds of menu rows apart.
+type MenuItemKind int
+
+const (
+	MenuCommand   MenuItemKind = iota // runs a command
+	MenuHeader                        // titles a section and folds it
+	MenuSeparator                     // blank line between sections
+)
+
+// MenuItem is one row of the menu.
+type MenuItem struct {
+	Kind     MenuItemKind
+	Command  Command // for MenuCommand
+	Section  Section // the section the row belongs to; unset for separators
+	Shortcut rune    // key that selects the item directly, 0 for none
+	Disabled bool    // shown, but skipped by the cursor and never selected
+}
+
+// Selectable reports whether the cursor can rest on the item.
+func (it MenuItem) Selectable() bool {
+	return it.Kind != MenuSeparator && !it.Disabled
+}
+
+// Menu owns the menu's items in display order and the cursor into them, so
+// the highlighted row is always the one that gets selected, whatever order
+// the commands were registered in.
+type Menu struct {
+	commands  []Command
+	items     []MenuItem
+	cursor    int
+	collapsed map[string]bool // folded sections, by category ID
+	disabled  map[string]bool // disabled commands, by name
+}
+
+// NewMenu builds the menu for cmds, grouped into sections, with the cursor
+// on the first command.
+func NewMenu(cmds []Command) *Menu {
+	m := &Menu{
+		commands:  cmds,
+		collapsed: map[string]bool{},
+		disabled:  map[string]bool{},
+	}
+	m.rebuild()
+	for i, it := range m.items {
+		if it.Kind == MenuCommand && it.Selectable() {
+			m.cursor = i
+			break
+		}
+	}
+	return m
+}
+
+// rebuild lays the items out again after folding or disabling, keeping the
+// cursor on the same row where possible.
+func (m *Menu) rebuild() {
+	current, hadCurrent := m.Selected()
+
+	m.items = nil
+	for i, section := range Sections(m.commands) {
+		if i > 0 {
+			m.items = append(m.items, MenuItem{Kind: MenuSeparator})
+		}
+		m.items = append(m.items, MenuItem{Kind: MenuHeader, Section: section})
+		if m.collapsed[section.Category.ID] {
+			continue
+		}
+		for _, cmd := range section.Commands {
+			m.items = append(m.items, MenuItem{
+				Kind:     MenuCommand,
+				Command:  cmd,
+				Section:  section,
+				Shortcut: cmd.Shortcut,
+				Disabled: m.disabled[cmd.Name],
+			})
+		}
+	}
+
+	if hadCurrent {
+		for i, it := range m.items {
+			if it.Kind == current.Kind && it.Command.Name == current.Command.Name &&
+				it.Section.Category.ID == current.Section.Category.ID && it.Selectable() {
+				m.cursor = i
+				return
+			}
+		}
+		// The row was folded away; fall back to its section's header
+		if m.collapsed[current.Section.Category.ID] {
+			for i, it := range m.items {
+				if it.Kind == MenuHeader && it.Section.Category.ID == current.Section.Category.ID {
+					m.cursor = i
+					return
+				}
+			}
+		}
+	}
+	m.cursor = min(m.cursor, len(m.items)-1)
+	if !m.valid(m.cursor) {
+		m.Down()
+		if !m.valid(m.cursor) {
+			m.Up()
+		}
+	}
+}
+
+// valid reports whether i is the index of a selectable item.
+func (m *Menu) valid(i int) bool {
+	return i >= 0 && i < len(m.items) && m.items[i].Selectable()
+}
+
+// Items returns the items in display order.
+func (m *Menu) Items() []MenuItem {
+	return m.items
+}
+
+// Cursor returns the index of the highlighted item.
+func (m *Menu) Cursor() int {
+	return m.cursor
+}
+
+// Selected returns the highlighted item.
+func (m *Menu) Selected() (MenuItem, bool) {
+	if !m.valid(m.cursor) {
+		return MenuItem{}, false
+	}
+	return m.items[m.cursor], true
+}
+
+// Position returns the 1-based position of the highlighted item among the
+// selectable ones, and how many there are, for announcing the selection.
+func (m *Menu) Position() (pos, total int) {
+	for i, it := range m.items {
+		if it.Selectable() {
+			total++
+			if i == m.cursor {
+				pos = total
+			}
+		}
+	}
+	return pos, total
+}
+
+// Up moves the cursor to the previous selectable item. It stops at the top.
+func (m *Menu) Up() {
+	for i := m.cursor - 1; i >= 0; i-- {
+		if m.items[i].Selectable() {
+			m.cursor = i
+			return
+		}
+	}
+}
+
+// Down moves the cursor to the next selectable item. It stops at the bottom.
+func (m *Menu) Down() {
+	for i := m.cursor + 1; i < len(m.items); i++ {
+		if m.items[i].Selectable() {
+			m.cursor = i
+			return
+		}
+	}
+}
+
+// Top moves the cursor to the first selectable item.
+func (m *Menu) Top() {
+	m.cursor = -1
+	m.Down()
+}
+
+// Bottom moves the cursor to the last selectable item.
+func (m *Menu) Bottom() {
+	m.cursor = len(m.items)
+	m.Up()
+}
+
+// SelectItem moves the cursor to the item at index i, as when it is
+// clicked. It reports false for items the cursor can't rest on.
+func (m *Menu) SelectItem(i int) bool {
+	if !m.valid(i) {
+		return false
+	}
+	m.cursor = i
+	return true
+}
+
+// SelectCommand moves the cursor to the named command, unfolding its section
+// if needed. It reports false, leaving the cursor alone, for commands that
+// aren't shown or are disabled.
+func (m *Menu) SelectCommand(name string) bool {
+	for _, cmd := range m.commands {
+		if cmd.Name == name && m.collapsed[cmd.Category] {
+			m.SetCollapsed(cmd.Category, false)
+		}
+	}
+	for i, it := range m.items {
+		if it.Kind == MenuCommand && it.Command.Name == name && it.Selectable() {
+			m.cursor = i
+			return true
+		}
+	}
+	return false
+}
+
+// SelectShortcut moves the cursor to the command bound to key r, unfolding
+// its section if needed, and returns it.
+func (m *Menu) SelectShortcut(r rune) (Command, bool) {
+	for _, cmd := range m.commands {
+		if cmd.Shortcut == r && cmd.Shortcut != 0 && m.SelectCommand(cmd.Name) {
+			return cmd, true
+		}
+	}
+	return Command{}, false
+}
+
+// Collapsed reports whether the section with category id is folded.
+func (m *Menu) Collapsed(id string) bool {
+	return m.collapsed[id]
+}
+
+// SetCollapsed folds or unfolds the section with category id.
+func (m *Menu) SetCollapsed(id string, folded bool) {
+	if m.collapsed[id] == folded {
+		return
+	}
+	if folded {
+		m.collapsed[id] = true
+	} else {
+		delete(m.collapsed, id)
+	}
+	m.rebuild()
+}
+
+// Fold folds or unfolds the section under the cursor and moves the cursor to
+// its header, which is shown in either state.
+func (m *Menu) Fold(folded bool) {
+	it, ok := m.Selected()
+	if !ok {
+		return
+	}
+	id := it.Section.Category.ID
+	m.SetCollapsed(id, folded)
+	for i, it := range m.items {
+		if it.Kind == MenuHeader && it.Section.Category.ID == id {
+			m.cursor = i
+			return
+		}
+	}
+}
+
+// SetDisabled greys out the named command, or enables it again. The cursor
+// moves off an item when it is disabled.
+func (m *Menu) SetDisabled(name string, disabled bool) {
+	if disabled {
+		m.disabled[name] = true
+	} else {
+		delete(m.disabled, name)
+	}
+	m.rebuild()
+}
@@ -0,0 +1,222 @@
+package main
+
+import "testing"
+
+// testCommands registers commands out of display order, with a system
+// command first and an unregistered category, to check that the menu
+// follows the sections rather than the registry.
+func testCommands() []Command {
+	return []Command{
+		{Name: "help", Category: "system", Shortcut: 'h'},
+		{Name: "about", Category: "portfolio", Shortcut: 'a'},
+		{Name: "date", Category: "system"},
+		{Name: "skills", Category: "portfolio", Shortcut: 's'},
+		{Name: "play", Category: "games"},
+	}
+}
+
+// selectedName returns the name of the highlighted command, or "#<id>" for a
+// section header.
+func selectedName(m *Menu) string {
+	it, ok := m.Selected()
+	switch {
+	case !ok:
+		return ""
+	case it.Kind == MenuHeader:
+		return "#" + it.Section.Category.ID
+	}
+	return it.Command.Name
+}
+
+func TestMenuLayout(t *testing.T) {
+	m := NewMenu(testCommands())
+
+	var got []string
+	for _, it := range m.Items() {
+		switch it.Kind {
+		case MenuSeparator:
+			got = append(got, "-")
+		case MenuHeader:
+			got = append(got, "#"+it.Section.Category.ID)
+		case MenuCommand:
+			got = append(got, it.Command.Name)
+		}
+	}
+	want := []string{"#portfolio", "about", "skills", "-", "#games", "play", "-", "#system", "help", "date"}
+	if len(got) != len(want) {
+		t.Fatalf("items = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("items = %v, want %v", got, want)
+		}
+	}
+
+	if name := selectedName(m); name != "about" {
+		t.Errorf("initial selection = %q, want %q", name, "about")
+	}
+}
+
+func TestMenuCursorMovement(t *testing.T) {
+	tests := []struct {
+		name  string
+		moves string // u = Up, d = Down, t = Top, b = Bottom
+		want  string
+	}{
+		{"start", "", "about"},
+		{"down", "d", "skills"},
+		{"down skips separator", "dd", "#games"},
+		{"down across sections", "ddddd", "help"},
+		{"down stops at bottom", "dddddddddd", "date"},
+		{"up to header", "u", "#portfolio"},
+		{"up stops at top", "uuu", "#portfolio"},
+		{"up skips separator", "dddu", "#games"},
+		{"top", "dddt", "#portfolio"},
+		{"bottom", "b", "date"},
+		{"bottom then up", "bu", "help"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := NewMenu(testCommands())
+			for _, mv := range tt.moves {
+				switch mv {
+				case 'u':
+					m.Up()
+				case 'd':
+					m.Down()
+				case 't':
+					m.Top()
+				case 'b':
+					m.Bottom()
+				}
+			}
+			if got := selectedName(m); got != tt.want {
+				t.Errorf("after %q selected %q, want %q", tt.moves, got, tt.want)
+			}
+			if it := m.Items()[m.Cursor()]; !it.Selectable() {
+				t.Errorf("cursor rests on unselectable item %+v", it)
+			}
+		})
+	}
+}
+
+func TestMenuPosition(t *testing.T) {
+	m := NewMenu(testCommands())
+	if pos, total := m.Position(); pos != 2 || total != 8 {
+		t.Errorf("Position() = %d, %d, want 2, 8", pos, total)
+	}
+	m.Bottom()
+	if pos, total := m.Position(); pos != 8 || total != 8 {
+		t.Errorf("Position() at bottom = %d, %d, want 8, 8", pos, total)
+	}
+}
+
+func TestMenuFold(t *testing.T) {
+	m := NewMenu(testCommands())
+	m.Down() // skills
+
+	m.Fold(true)
+	if !m.Collapsed("portfolio") {
+		t.Fatal("portfolio not collapsed")
+	}
+	if got := selectedName(m); got != "#portfolio" {
+		t.Errorf("after fold selected %q, want %q", got, "#portfolio")
+	}
+	m.Down()
+	if got := selectedName(m); got != "#games" {
+		t.Errorf("down from folded header selected %q, want %q", got, "#games")
+	}
+
+	m.Up()
+	m.Fold(false)
+	if m.Collapsed("portfolio") {
+		t.Fatal("portfolio still collapsed")
+	}
+	m.Down()
+	if got := selectedName(m); got != "about" {
+		t.Errorf("after unfold selected %q, want %q", got, "about")
+	}
+}
+
+func TestMenuFoldKeepsSelectionElsewhere(t *testing.T) {
+	m := NewMenu(testCommands())
+	m.Bottom() // date
+	m.SetCollapsed("portfolio", true)
+	if got := selectedName(m); got != "date" {
+		t.Errorf("folding another section moved the selection to %q", got)
+	}
+}
+
+func TestMenuSelectCommand(t *testing.T) {
+	m := NewMenu(testCommands())
+	m.SetCollapsed("system", true)
+
+	if !m.SelectCommand("date") {
+		t.Fatal("SelectCommand(date) = false")
+	}
+	if m.Collapsed("system") {
+		t.Error("SelectCommand did not unfold the section")
+	}
+	if got := selectedName(m); got != "date" {
+		t.Errorf("selected %q, want %q", got, "date")
+	}
+
+	if m.SelectCommand("missing") {
+		t.Error("SelectCommand(missing) = true")
+	}
+	if got := selectedName(m); got != "date" {
+		t.Errorf("failed SelectCommand moved the selection to %q", got)
+	}
+}
+
+func TestMenuSelectShortcut(t *testing.T) {
+	tests := []struct {
+		key    rune
+		want   string
+		wantOK bool
+	}{
+		{'a', "about", true},
+		{'s', "skills", true},
+		{'h', "help", true},
+		{'x', "", false},
+		{0, "", false},
+	}
+	for _, tt := range tests {
+		m := NewMenu(testCommands())
+		m.Bottom()
+		cmd, ok := m.SelectShortcut(tt.key)
+		if ok != tt.wantOK || cmd.Name != tt.want {
+			t.Errorf("SelectShortcut(%q) = %q, %v, want %q, %v", tt.key, cmd.Name, ok, tt.want, tt.wantOK)
+		}
+		if ok && selectedName(m) != tt.want {
+			t.Errorf("SelectShortcut(%q) left %q selected", tt.key, selectedName(m))
+		}
+	}
+}
+
+func TestMenuDisabled(t *testing.T) {
+	m := NewMenu(testCommands())
+	m.SetDisabled("about", true)
+
+	if got := selectedName(m); got != "skills" {
+		t.Errorf("disabling the selection left %q selected, want %q", got, "skills")
+	}
+	m.Up()
+	if got := selectedName(m); got != "#portfolio" {
+		t.Errorf("up skipped to %q, want %q", got, "#portfolio")
+	}
+	if m.SelectCommand("about") {
+		t.Error("SelectCommand selected a disabled command")
+	}
+	if _, ok := m.SelectShortcut('a'); ok {
+		t.Error("SelectShortcut selected a disabled command")
+	}
+	if _, total := m.Position(); total != 7 {
+		t.Errorf("Position total = %d, want 7", total)
+	}
+
+	m.SetDisabled("about", false)
+	if !m.SelectCommand("about") {
+		t.Error("SelectCommand(about) = false after enabling it")
+	}
+}
@@ -0,0 +1,156 @@
+package main
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// Mouse support works on zones: renderers wrap the clickable parts of a view
+// in invisible markers, and View strips them again while noting where each
+// part landed on screen. Clicks are matched against the frame the visitor is
+// actually looking at, however the layout above them changes.
+
+// zoneMarker delimits a zone. It is an OSC sequence, which terminals ignore,
+// so a marker that slipped through would not garble the screen.
+var zoneMarker = regexp.MustCompile("\x1b\\]zone;([^\x07]*)\x07")
+
+// zoneMark wraps s in markers for the zone id. The same id must not be used
+// twice in one view.
+func zoneMark(id, s string) string {
+	m := "\x1b]zone;" + id + "\x07"
+	return m + s + m
+}
+
+// Zone is a clickable part of the last rendered view, in screen cells. It
+// runs from (StartX, StartY) to just before (EndX, EndY), like text.
+type Zone struct {
+	ID                         string
+	StartX, StartY, EndX, EndY int
+}
+
+// Contains reports whether the cell at x, y is part of the zone.
+func (z Zone) Contains(x, y int) bool {
+	if y < z.StartY || y > z.EndY {
+		return false
+	}
+	if y == z.StartY && x < z.StartX {
+		return false
+	}
+	if y == z.EndY && x >= z.EndX {
+		return false
+	}
+	return true
+}
+
+// Zones holds the zones of the last rendered view. Model copies share one
+// instance, since View has a value receiver but the zones must outlive it.
+type Zones struct {
+	zones []Zone
+}
+
+// Scan strips the zone markers from view and records where each zone is.
+// Views taller than height lose their top lines on screen, so rows are
+// counted from the first visible line.
+func (z *Zones) Scan(view string, height int) string {
+	lines := strings.Split(view, "\n")
+	offset := 0
+	if height > 0 && len(lines) > height {
+		offset = len(lines) - height
+	}
+
+	z.zones = z.zones[:0]
+	open := map[string]Zone{}
+	for y, line := range lines {
+		if !strings.Contains(line, "\x1b]zone;") {
+			continue
+		}
+		var clean strings.Builder
+		for {
+			loc := zoneMarker.FindStringSubmatchIndex(line)
+			if loc == nil {
+				clean.WriteString(line)
+				break
+			}
+			clean.WriteString(line[:loc[0]])
+			id := line[loc[2]:loc[3]]
+			x := ansi.StringWidth(clean.String())
+			if zone, ok := open[id]; ok {
+				zone.EndX, zone.EndY = x, y-offset
+				z.zones = append(z.zones, zone)
+				delete(open, id)
+			} else {
+				open[id] = Zone{ID: id, StartX: x, StartY: y - offset}
+			}
+			line = line[loc[1]:]
+		}
+		lines[y] = clean.String()
+	}
+	return strings.Join(lines, "\n")
+}
+
+// At returns the zone under the cell at x, y.
+func (z *Zones) At(x, y int) (Zone, bool) {
+	for _, zone := range z.zones {
+		if zone.Contains(x, y) {
+			return zone, true
+		}
+	}
+	return Zone{}, false
+}
+
+// sgrMouse matches xterm SGR mouse reports (mode 1006): ESC [ < button ; x ;
+// y, ending in M for presses and motion or m for releases.
+var sgrMouse = regexp.MustCompile(`\x1b\[<(\d+);(\d+);(\d+)([Mm])`)
+
+// ParseSGRMouse decodes the SGR mouse reports in input, as xterm.js sends
+// them once mouse tracking is on. Coordinates are 1-based on the wire and
+// 0-based in the messages, as with tea.Program.
+func ParseSGRMouse(input string) []tea.MouseMsg {
+	var msgs []tea.MouseMsg
+	for _, m := range sgrMouse.FindAllStringSubmatch(input, -1) {
+		b, _ := strconv.Atoi(m[1])
+		x, _ := strconv.Atoi(m[2])
+		y, _ := strconv.Atoi(m[3])
+
+		msg := tea.MouseMsg{
+			X:     x - 1,
+			Y:     y - 1,
+			Shift: b&4 != 0,
+			Alt:   b&8 != 0,
+			Ctrl:  b&16 != 0,
+		}
+		switch {
+		case b&64 != 0:
+			msg.Button = tea.MouseButtonWheelUp + tea.MouseButton(b&3)
+			msg.Action = tea.MouseActionPress
+		case b&32 != 0:
+			msg.Button = sgrButton(b)
+			msg.Action = tea.MouseActionMotion
+		case m[4] == "m":
+			msg.Button = sgrButton(b)
+			msg.Action = tea.MouseActionRelease
+		default:
+			msg.Button = sgrButton(b)
+			msg.Action = tea.MouseActionPress
+		}
+		msgs = append(msgs, msg)
+	}
+	return msgs
+}
+
+// sgrButton maps the low bits of an SGR button code to a button.
+func sgrButton(b int) tea.MouseButton {
+	switch b & 3 {
+	case 0:
+		return tea.MouseButtonLeft
+	case 1:
+		return tea.MouseButtonMiddle
+	case 2:
+		return tea.MouseButtonRight
+	}
+	return tea.MouseButtonNone
+}
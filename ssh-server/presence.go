@@ -0,0 +1,186 @@
+package main
+
+import (
+	"errors"
+	"hash/fnv"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// PresenceMsg tells a running TUI that visitors came or left, so it can
+// update its count of who is online.
+type PresenceMsg struct{}
+
+// Presence is what other visitors may see of a session: an anonymous name,
+// how it is connected and what it is looking at.
+type Presence struct {
+	Name      string    `json:"name" yaml:"name"`
+	Transport Transport `json:"transport" yaml:"transport"`
+	View      string    `json:"view" yaml:"view"`
+	Since     time.Time `json:"since" yaml:"since"`
+
+	id string
+}
+
+// Visitor names are an adjective and an animal picked from the session ID,
+// so they reveal nothing about the visitor and stay the same while they
+// browse.
+var (
+	nameAdjectives = []string{
+		"amber", "brave", "calm", "clever", "cosmic", "crimson", "curious", "dapper",
+		"eager", "fuzzy", "gentle", "golden", "happy", "jolly", "keen", "lucky",
+		"mellow", "misty", "nimble", "quiet", "rapid", "rusty", "silver", "sleepy",
+		"sunny", "swift", "tidy", "violet", "witty", "zesty",
+	}
+	nameAnimals = []string{
+		"badger", "beaver", "crane", "dolphin", "falcon", "ferret", "fox", "gecko",
+		"hedgehog", "heron", "koala", "lemur", "lynx", "marmot", "moose", "narwhal",
+		"octopus", "otter", "owl", "panda", "penguin", "puffin", "quokka", "raccoon",
+		"seal", "sloth", "tapir", "toucan", "walrus", "yak",
+	}
+)
+
+// visitorName returns the anonymous name of the session with the given ID.
+func visitorName(id string) string {
+	h := fnv.New32a()
+	h.Write([]byte(id))
+	n := h.Sum32()
+	return nameAdjectives[n%uint32(len(nameAdjectives))] + "-" + nameAnimals[n/uint32(len(nameAdjectives))%uint32(len(nameAnimals))]
+}
+
+// SetView records what the visitor is looking at, such as "menu" or a
+// command name.
+func (s *Session) SetView(view string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.view = view
+}
+
+// Online returns the visitors browsing with a TUI, longest connected first.
+// Exec requests come and go too fast to count.
+func (r *SessionRegistry) Online() []Presence {
+	var online []Presence
+	for _, s := range r.Sessions() {
+		s.mu.Lock()
+		if s.send != nil {
+			online = append(online, Presence{Name: s.Name, Transport: s.Transport, View: s.view, Since: s.Started, id: s.ID})
+		}
+		s.mu.Unlock()
+	}
+	sort.Slice(online, func(i, j int) bool {
+		if !online[i].Since.Equal(online[j].Since) {
+			return online[i].Since.Before(online[j].Since)
+		}
+		return online[i].id < online[j].id
+	})
+	return online
+}
+
+// whoDocument lists the visitors online, marking the one asking.
+func whoDocument(ctx *CommandContext) (Document, error) {
+	if ctx.Sessions == nil {
+		return nil, errors.New(ctx.Catalog.T("who.unavailable"))
+	}
+	v := whoView{online: ctx.Sessions.Online(), now: time.Now()}
+	if ctx.Session != nil {
+		v.you = ctx.Session.ID
+	}
+	return v, nil
+}
+
+// whoView is the visitors online. It is encoded as the bare list.
+type whoView struct {
+	online []Presence
+	you    string // ID of the asking session
+	now    time.Time
+}
+
+// MarshalJSON encodes the visitors.
+func (v whoView) MarshalJSON() ([]byte, error) {
+	return jsonList(v.online)
+}
+
+// MarshalYAML encodes the visitors.
+func (v whoView) MarshalYAML() (interface{}, error) {
+	if v.online == nil {
+		return []Presence{}, nil
+	}
+	return v.online, nil
+}
+
+// since tells how long p has been connected, in minutes.
+func (v whoView) since(p Presence, t *Catalog) string {
+	return t.T("who.since", int(v.now.Sub(p.Since).Minutes()))
+}
+
+// name returns p's name, marked when it is the visitor asking.
+func (v whoView) name(p Presence, t *Catalog) string {
+	if p.id == v.you {
+		return p.Name + " " + t.T("who.you")
+	}
+	return p.Name
+}
+
+// transportName names how p is connected.
+func transportName(t *Catalog, tr Transport) string {
+	return t.T("who.transport." + string(tr))
+}
+
+// Styled renders one line per visitor.
+func (v whoView) Styled(st *Styles, t *Catalog) string {
+	var sb strings.Builder
+
+	sb.WriteString(st.Header(strings.ToUpper(t.T("who.heading"))))
+	sb.WriteString("\n\n")
+	sb.WriteString(st.Dim(t.T("who.count", len(v.online))) + "\n\n")
+	width := 0
+	for _, p := range v.online {
+		width = max(width, len([]rune(v.name(p, t))))
+	}
+	for _, p := range v.online {
+		name := v.name(p, t)
+		pad := strings.Repeat(" ", width-len([]rune(name)))
+		sb.WriteString(st.Prompt.Render("●") + " " + st.Label(name) + pad + "  " +
+			st.Value(p.View) + "  " + st.Dim(transportName(t, p.Transport)+" · "+v.since(p, t)) + "\n")
+	}
+
+	return strings.TrimSuffix(sb.String(), "\n")
+}
+
+// Plain renders the visitors as a table.
+func (v whoView) Plain(t *Catalog) string {
+	var sb strings.Builder
+
+	sb.WriteString(strings.ToUpper(t.T("who.heading")) + "\n\n")
+	for _, p := range v.online {
+		sb.WriteString(v.name(p, t) + " | " + p.View + " | " + string(p.Transport) + " | " + strconv.Itoa(int(v.now.Sub(p.Since).Minutes())) + "m\n")
+	}
+
+	return sb.String()
+}
+
+// Accessible renders each visitor as a sentence.
+func (v whoView) Accessible(t *Catalog) string {
+	var sb strings.Builder
+
+	sb.WriteString(strings.ToUpper(t.T("who.heading")) + ", " + t.T("who.count", len(v.online)) + "\n\n")
+	for _, p := range v.online {
+		sb.WriteString(t.T("who.line", v.name(p, t), p.View, transportName(t, p.Transport), v.since(p, t)) + "\n")
+	}
+
+	return sb.String()
+}
+
+// Markdown renders the visitors as a list.
+func (v whoView) Markdown(t *Catalog) string {
+	var sb strings.Builder
+
+	sb.WriteString("# " + t.T("who.heading") + "\n\n")
+	for _, p := range v.online {
+		sb.WriteString("- **" + v.name(p, t) + "** `" + p.View + "` " + transportName(t, p.Transport) + ", " + v.since(p, t) + "\n")
+	}
+
+	return sb.String()
+}
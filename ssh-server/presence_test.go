@@ -0,0 +1,51 @@
+package main
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestPresence(t *testing.T) {
+	r := NewSessionRegistry()
+	a := NewSession("a", TransportSSH, "alice", "", nil)
+	b := NewSession("b", TransportWebSocket, "guest", "", nil)
+	exec := NewSession("c", TransportSSH, "carol", "", nil)
+	for _, s := range []*Session{a, b, exec} {
+		if err := r.Register(s); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Only sessions with a TUI are online, and they hear of each other
+	notified := make(chan string, 4)
+	a.SetSender(func(msg tea.Msg) {
+		if _, ok := msg.(PresenceMsg); ok {
+			notified <- "a"
+		}
+	})
+	<-notified
+	b.SetSender(func(tea.Msg) {})
+	<-notified
+	b.SetView("projects")
+
+	online := r.Online()
+	if len(online) != 2 || online[0].Name != a.Name || online[1].View != "projects" {
+		t.Fatalf("online = %+v, want a then b on projects", online)
+	}
+	if a.Name == "" || a.Name == b.Name {
+		t.Errorf("names %q and %q, want distinct anonymous names", a.Name, b.Name)
+	}
+
+	r.Unregister("b")
+	<-notified
+	r.Unregister("c")
+	if n := len(r.Online()); n != 1 {
+		t.Errorf("after b left: %d online, want 1", n)
+	}
+	select {
+	case <-notified:
+		t.Error("an exec session leaving was announced")
+	default:
+	}
+}
@@ -0,0 +1,291 @@
+package main
+
+import (
+	"sort"
+	"strings"
+)
+
+// projectsDocument lists the projects, or only those tagged --tag, and
+// shows one of them in detail when given its id (`projects ssh-portfolio`).
+// In the TUI every listed project is a choice that opens its page.
+func projectsDocument(ctx *CommandContext) (Document, error) {
+	t := ctx.Catalog
+	c, err := LoadContentFor(t.Locale)
+	if err != nil {
+		return nil, err
+	}
+
+	switch len(ctx.Args) {
+	case 0:
+	case 1:
+		for _, p := range c.Projects {
+			if strings.EqualFold(p.ID, ctx.Args[0]) {
+				return projectView{Project: p, width: markdownWidth(ctx.Width)}, nil
+			}
+		}
+		ids := make([]string, len(c.Projects))
+		for i, p := range c.Projects {
+			ids[i] = p.ID
+		}
+		return nil, &UsageError{t.T("projects.unknown", ctx.Args[0], strings.Join(ids, ", "))}
+	default:
+		return nil, &UsageError{t.T("projects.usage")}
+	}
+
+	v := projectsView{tag: ctx.Flags["tag"], tags: c.Projects.Tags()}
+	for _, p := range c.Projects {
+		if v.tag == "" || p.HasTag(v.tag) {
+			v.list = append(v.list, p)
+			ctx.AddChoice(p.Name, "projects", p.ID)
+		}
+	}
+	return v, nil
+}
+
+// Tags returns every tag used by the projects, sorted and without
+// duplicates.
+func (l ProjectList) Tags() []string {
+	seen := map[string]bool{}
+	var tags []string
+	for _, p := range l {
+		for _, tag := range p.Tags {
+			if key := strings.ToLower(tag); !seen[key] {
+				seen[key] = true
+				tags = append(tags, tag)
+			}
+		}
+	}
+	sort.Strings(tags)
+	return tags
+}
+
+// statusName is the localized name of a project status.
+func statusName(t *Catalog, status string) string {
+	if status == "" {
+		return ""
+	}
+	return t.T("project.status." + status)
+}
+
+// projectsView is the project list, filtered by tag. It is encoded as the
+// bare list so the API returns the same shape with or without a filter.
+type projectsView struct {
+	list ProjectList
+	tag  string   // filter, "" for every project
+	tags []string // every tag, suggested by the hint
+}
+
+// MarshalJSON encodes the filtered list.
+func (v projectsView) MarshalJSON() ([]byte, error) {
+	return jsonList(v.list)
+}
+
+// MarshalYAML encodes the filtered list.
+func (v projectsView) MarshalYAML() (interface{}, error) {
+	if v.list == nil {
+		return []Project{}, nil
+	}
+	return v.list, nil
+}
+
+// Styled renders one entry per project with its year, status and stack.
+func (v projectsView) Styled(st *Styles, t *Catalog) string {
+	var sb strings.Builder
+
+	sb.WriteString(st.Header(strings.ToUpper(t.T("projects.heading"))))
+	sb.WriteString("\n\n")
+	if v.tag != "" {
+		sb.WriteString(st.Label(t.T("field.tag")+" ") + st.Value(v.tag) + "\n\n")
+	}
+	if len(v.list) == 0 {
+		sb.WriteString(st.Dim(t.T("projects.none", v.tag, strings.Join(v.tags, ", "))))
+		return sb.String()
+	}
+
+	for _, p := range v.list {
+		meta := p.Year
+		if s := statusName(t, p.Status); s != "" {
+			meta += " · " + s
+		}
+		sb.WriteString(st.Label(p.Name) + "  " + st.Dim(meta) + "\n")
+		body := st.Value(strings.Join(p.Tags, " · ")) + "\n" + RenderMarkdown(p.Description, st, 56)
+		sb.WriteString(st.Content.Render(body) + "\n")
+	}
+	sb.WriteString(st.Dim(t.T("projects.hint", strings.Join(v.tags, ", "))))
+
+	return sb.String()
+}
+
+// Plain renders the list without styling.
+func (v projectsView) Plain(t *Catalog) string {
+	var sb strings.Builder
+
+	sb.WriteString(strings.ToUpper(t.T("projects.heading")) + "\n")
+	if v.tag != "" {
+		sb.WriteString(t.T("field.tag") + " " + v.tag + "\n")
+	}
+	if len(v.list) == 0 {
+		sb.WriteString("\n" + t.T("projects.none", v.tag, strings.Join(v.tags, ", ")) + "\n")
+		return sb.String()
+	}
+	for _, p := range v.list {
+		sb.WriteString("\n" + p.Name + " (" + p.ID + ")\n")
+		meta := p.Year
+		if s := statusName(t, p.Status); s != "" {
+			meta += " | " + s
+		}
+		sb.WriteString(meta + " | " + strings.Join(p.Tags, ", ") + "\n")
+		sb.WriteString(RenderMarkdown(p.Description, nil, 0) + "\n")
+	}
+
+	return sb.String()
+}
+
+// Accessible renders each project as labeled lines.
+func (v projectsView) Accessible(t *Catalog) string {
+	var sb strings.Builder
+
+	sb.WriteString(strings.ToUpper(t.T("projects.heading")) + ", " + t.T("projects.count", len(v.list)) + "\n")
+	if v.tag != "" {
+		sb.WriteString(t.T("field.tag") + " " + v.tag + "\n")
+	}
+	if len(v.list) == 0 {
+		sb.WriteString("\n" + t.T("projects.none", v.tag, strings.Join(v.tags, ", ")) + "\n")
+		return sb.String()
+	}
+	for _, p := range v.list {
+		sb.WriteString("\n" + t.T("field.project") + " " + p.Name + "\n")
+		sb.WriteString(t.T("field.year") + " " + p.Year + "\n")
+		if p.Status != "" {
+			sb.WriteString(t.T("field.status") + " " + statusName(t, p.Status) + "\n")
+		}
+		sb.WriteString(t.T("field.stack") + " " + strings.Join(p.Tags, ", ") + "\n")
+		sb.WriteString(RenderMarkdown(p.Description, nil, 0) + "\n")
+	}
+	sb.WriteString("\n" + t.T("projects.hint", strings.Join(v.tags, ", ")) + "\n")
+
+	return sb.String()
+}
+
+// Markdown renders the list as Markdown.
+func (v projectsView) Markdown(t *Catalog) string {
+	var sb strings.Builder
+
+	sb.WriteString("# " + t.T("projects.heading") + "\n")
+	if v.tag != "" {
+		sb.WriteString("\n**" + t.T("field.tag") + "** `" + v.tag + "`\n")
+	}
+	if len(v.list) == 0 {
+		sb.WriteString("\n" + t.T("projects.none", v.tag, strings.Join(v.tags, ", ")) + "\n")
+		return sb.String()
+	}
+	for _, p := range v.list {
+		name := p.Name
+		if p.URL != "" {
+			name = "[" + name + "](" + markdownURL(p.URL) + ")"
+		}
+		sb.WriteString("\n## " + name + "\n\n")
+		sb.WriteString(p.meta(t) + "\n\n")
+		sb.WriteString(p.Description + "\n")
+	}
+
+	return sb.String()
+}
+
+// meta is the Markdown line with the project's year, status and stack.
+func (p Project) meta(t *Catalog) string {
+	meta := "_" + p.Year + "_"
+	if s := statusName(t, p.Status); s != "" {
+		meta += " | " + s
+	}
+	if len(p.Tags) > 0 {
+		meta += " | `" + strings.Join(p.Tags, "` `") + "`"
+	}
+	return meta
+}
+
+// Markdown renders the project page: its details, then the write-up or
+// the description when there is none. It is also the project's file in
+// ~/projects.
+func (p Project) Markdown(t *Catalog) string {
+	var sb strings.Builder
+
+	sb.WriteString("# " + p.Name + "\n\n")
+	sb.WriteString(p.meta(t) + "\n\n")
+	if p.Repo != "" {
+		sb.WriteString("- **" + t.T("field.repo") + "** <" + markdownURL(p.Repo) + ">\n")
+	}
+	if p.URL != "" {
+		sb.WriteString("- **" + t.T("field.website") + "** <" + markdownURL(p.URL) + ">\n")
+	}
+	if p.Repo != "" || p.URL != "" {
+		sb.WriteString("\n")
+	}
+	sb.WriteString(p.text() + "\n")
+
+	return sb.String()
+}
+
+// text is the write-up, or the description when there is none.
+func (p Project) text() string {
+	if body := strings.TrimSpace(p.Body); body != "" {
+		return body
+	}
+	return p.Description
+}
+
+// projectView is the page of one project, with the write-up wrapped to the
+// terminal.
+type projectView struct {
+	Project `yaml:",inline"`
+	width   int
+}
+
+// Styled renders the project's details and its write-up.
+func (v projectView) Styled(st *Styles, t *Catalog) string {
+	var sb strings.Builder
+
+	sb.WriteString(st.Header(strings.ToUpper(v.Name)))
+	sb.WriteString("\n\n")
+	if v.Status != "" {
+		sb.WriteString(st.Label(t.T("field.status")+" ") + st.Value(statusName(t, v.Status)) + "\n")
+	}
+	sb.WriteString(st.Label(t.T("field.year")+" ") + st.Value(v.Year) + "\n")
+	sb.WriteString(st.Label(t.T("field.stack")+" ") + st.Value(strings.Join(v.Tags, ", ")) + "\n")
+	if v.Repo != "" {
+		sb.WriteString(st.Label(t.T("field.repo")+" ") + st.Link(st.Value(v.Repo), v.Repo) + "\n")
+	}
+	if v.URL != "" {
+		sb.WriteString(st.Label(t.T("field.website")+" ") + st.Link(st.Value(v.URL), v.URL) + "\n")
+	}
+	sb.WriteString("\n" + RenderMarkdown(v.text(), st, v.width) + "\n\n")
+	sb.WriteString(st.Dim(t.T("projects.back")))
+
+	return sb.String()
+}
+
+// Plain renders the project page without styling.
+func (v projectView) Plain(t *Catalog) string {
+	var sb strings.Builder
+
+	sb.WriteString(strings.ToUpper(v.Name) + "\n\n")
+	if v.Status != "" {
+		sb.WriteString(t.T("field.status") + " " + statusName(t, v.Status) + "\n")
+	}
+	sb.WriteString(t.T("field.year") + " " + v.Year + "\n")
+	sb.WriteString(t.T("field.stack") + " " + strings.Join(v.Tags, ", ") + "\n")
+	if v.Repo != "" {
+		sb.WriteString(t.T("field.repo") + " " + v.Repo + "\n")
+	}
+	if v.URL != "" {
+		sb.WriteString(t.T("field.website") + " " + v.URL + "\n")
+	}
+	sb.WriteString("\n" + RenderMarkdown(v.text(), nil, 0) + "\n")
+
+	return sb.String()
+}
+
+// Markdown renders the project page as Markdown.
+func (v projectView) Markdown(t *Catalog) string {
+	return v.Project.Markdown(t)
+}
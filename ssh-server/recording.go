@@ -0,0 +1,373 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/log"
+	"github.com/charmbracelet/ssh"
+)
+
+// RecordMode decides which sessions are recorded.
+type RecordMode string
+
+const (
+	RecordOff   RecordMode = "off"
+	RecordOptIn RecordMode = "opt-in" // visitors who ask, with RECORD=1 or ?record=1
+	RecordAll   RecordMode = "all"
+)
+
+// RecordConfig controls session recording and how long recordings are kept.
+type RecordConfig struct {
+	Mode     RecordMode
+	Dir      string
+	Keep     time.Duration // recordings older than this are deleted, 0 keeps them
+	MaxFiles int           // most recordings kept, 0 for no limit
+	MaxBytes int64         // a recording stops growing past this size, 0 for no limit
+}
+
+// DefaultRecordConfig records nothing; recordings that are turned on go to
+// DATA_DIR/recordings and are kept for a week.
+func DefaultRecordConfig() RecordConfig {
+	return RecordConfig{
+		Mode:     RecordOff,
+		Dir:      filepath.Join(dataDir, "recordings"),
+		Keep:     7 * 24 * time.Hour,
+		MaxFiles: 200,
+		MaxBytes: 20 << 20,
+	}
+}
+
+// RecordConfigFromEnv builds a RecordConfig from RECORD_* environment
+// variables. Invalid values are reported and ignored rather than aborting
+// startup.
+func RecordConfigFromEnv() (RecordConfig, []error) {
+	cfg := DefaultRecordConfig()
+	var errs []error
+
+	if v := os.Getenv("RECORD_SESSIONS"); v != "" {
+		switch mode := RecordMode(strings.ToLower(v)); mode {
+		case RecordOff, RecordOptIn, RecordAll:
+			cfg.Mode = mode
+		default:
+			errs = append(errs, fmt.Errorf("RECORD_SESSIONS: expected off, opt-in or all, got %q", v))
+		}
+	}
+
+	if v := os.Getenv("RECORD_DIR"); v != "" {
+		cfg.Dir = v
+	}
+
+	if v := os.Getenv("RECORD_KEEP"); v != "" {
+		// Days are the natural unit here, which time.ParseDuration lacks
+		keep, err := time.ParseDuration(v)
+		if days, ok := strings.CutSuffix(v, "d"); ok {
+			var n int
+			n, err = strconv.Atoi(days)
+			keep = time.Duration(n) * 24 * time.Hour
+		}
+		if err != nil || keep < 0 {
+			errs = append(errs, fmt.Errorf("RECORD_KEEP: expected a duration such as 7d or 12h, got %q", v))
+		} else {
+			cfg.Keep = keep
+		}
+	}
+
+	if v := os.Getenv("RECORD_MAX_FILES"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			errs = append(errs, fmt.Errorf("RECORD_MAX_FILES: expected a non-negative integer, got %q", v))
+		} else {
+			cfg.MaxFiles = n
+		}
+	}
+
+	if v := os.Getenv("RECORD_MAX_BYTES"); v != "" {
+		n, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || n < 0 {
+			errs = append(errs, fmt.Errorf("RECORD_MAX_BYTES: expected a non-negative integer, got %q", v))
+		} else {
+			cfg.MaxBytes = n
+		}
+	}
+
+	return cfg, errs
+}
+
+// SSHRecord reports whether an SSH client asked to be recorded, with
+// RECORD=1 in its environment (ssh -o SetEnv=RECORD=1).
+func SSHRecord(s ssh.Session) bool {
+	on, _ := parseSwitch(lookupEnv(s.Environ(), "RECORD"))
+	return on
+}
+
+// WebSocketRecord reports whether a WebSocket client asked to be recorded
+// in the handshake query (?record=1).
+func WebSocketRecord(query url.Values) bool {
+	on, _ := parseSwitch(query.Get("record"))
+	return on
+}
+
+// Recordings starts session recordings in asciicast v2 format and deletes
+// the ones past the retention policy.
+type Recordings struct {
+	cfg    RecordConfig
+	logger *log.Logger
+	mu     sync.Mutex // serializes pruning
+}
+
+// NewRecordings creates the recorder of a server.
+func NewRecordings(cfg RecordConfig, logger *log.Logger) *Recordings {
+	return &Recordings{cfg: cfg, logger: logger}
+}
+
+// Wanted reports whether a session should be recorded, given whether the
+// visitor asked to be.
+func (r *Recordings) Wanted(optIn bool) bool {
+	switch r.cfg.Mode {
+	case RecordAll:
+		return true
+	case RecordOptIn:
+		return optIn
+	}
+	return false
+}
+
+// Start records the session with the given ID, pruning old recordings
+// first so the new one fits within MaxFiles.
+func (r *Recordings) Start(id string, transport Transport, width, height int, term string) (*Recorder, error) {
+	if err := os.MkdirAll(r.cfg.Dir, 0o700); err != nil {
+		return nil, err
+	}
+	if err := r.prune(1); err != nil {
+		r.logger.Warn("Failed to prune recordings", "error", err)
+	}
+
+	now := time.Now()
+	name := now.UTC().Format("20060102-150405") + "-" + string(transport) + "-" + id + ".cast"
+	path := filepath.Join(r.cfg.Dir, name)
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+	if err != nil {
+		return nil, err
+	}
+	rec, err := NewRecorder(f, RecordHeader{
+		Width:     width,
+		Height:    height,
+		Timestamp: now.Unix(),
+		Title:     "genar.me " + string(transport) + " session " + id,
+		Env:       map[string]string{"TERM": term},
+	}, r.cfg.MaxBytes)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	r.logger.Info("Recording session", "session", id, "file", path)
+	return rec, nil
+}
+
+// Prune deletes the recordings past the retention policy.
+func (r *Recordings) Prune() error {
+	return r.prune(0)
+}
+
+// prune deletes recordings older than Keep, then the oldest ones until
+// room more fit within MaxFiles.
+func (r *Recordings) prune(room int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	entries, err := os.ReadDir(r.cfg.Dir)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	type recording struct {
+		path string
+		mod  time.Time
+	}
+	var list []recording
+	for _, e := range entries {
+		if e.IsDir() || filepath.Ext(e.Name()) != ".cast" {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil {
+			continue
+		}
+		list = append(list, recording{filepath.Join(r.cfg.Dir, e.Name()), info.ModTime()})
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].mod.Before(list[j].mod) })
+
+	var errs []error
+	cutoff := time.Now().Add(-r.cfg.Keep)
+	for i, rec := range list {
+		old := r.cfg.Keep > 0 && rec.mod.Before(cutoff)
+		over := r.cfg.MaxFiles > 0 && len(list)-i+room > r.cfg.MaxFiles
+		if !old && !over {
+			break
+		}
+		if err := os.Remove(rec.path); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		r.logger.Debug("Deleted recording", "file", rec.path)
+	}
+	if len(errs) > 0 {
+		return errs[0]
+	}
+	return nil
+}
+
+// RecordHeader is the first line of an asciicast v2 file.
+type RecordHeader struct {
+	Version   int               `json:"version"`
+	Width     int               `json:"width"`
+	Height    int               `json:"height"`
+	Timestamp int64             `json:"timestamp,omitempty"`
+	Title     string            `json:"title,omitempty"`
+	Env       map[string]string `json:"env,omitempty"`
+}
+
+// Recorder writes a session's output and resizes as an asciicast v2
+// recording: a header line, then one JSON event per line with the seconds
+// since the start. It is safe for concurrent use.
+type Recorder struct {
+	mu      sync.Mutex
+	w       *bufio.Writer
+	c       io.Closer
+	start   time.Time
+	now     func() time.Time
+	size    int64
+	max     int64
+	pending []byte // an incomplete UTF-8 sequence, completed by the next output
+	err     error  // the first write error; recording stops there
+}
+
+// NewRecorder writes the asciicast header to w and records into it until
+// Close, which closes w. Output stops being recorded past max bytes, unless
+// max is 0.
+func NewRecorder(w io.WriteCloser, header RecordHeader, max int64) (*Recorder, error) {
+	header.Version = 2
+	line, err := json.Marshal(header)
+	if err != nil {
+		return nil, err
+	}
+	r := &Recorder{w: bufio.NewWriter(w), c: w, start: time.Now(), now: time.Now, max: max}
+	r.write(line)
+	return r, r.err
+}
+
+// Output records data the session wrote to the terminal. Escape sequences
+// may be split across writes, but asciicast events are JSON strings, so a
+// multi-byte character cut at the end is held for the next call.
+func (r *Recorder) Output(data []byte) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	data = append(r.pending, data...)
+	r.pending = nil
+	for i := len(data) - 1; i >= 0 && i >= len(data)-utf8.UTFMax; i-- {
+		if utf8.RuneStart(data[i]) {
+			if !utf8.FullRune(data[i:]) {
+				r.pending = append([]byte(nil), data[i:]...)
+				data = data[:i]
+			}
+			break
+		}
+	}
+	if len(data) > 0 {
+		r.event("o", string(data))
+	}
+}
+
+// Resize records the terminal changing size.
+func (r *Recorder) Resize(width, height int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.event("r", strconv.Itoa(width)+"x"+strconv.Itoa(height))
+}
+
+// Writer returns a writer that writes to w and records what it wrote.
+func (r *Recorder) Writer(w io.Writer) io.Writer {
+	return recordingWriter{w: w, r: r}
+}
+
+// Filter is a tea.WithFilter filter recording the program's resizes.
+func (r *Recorder) Filter(_ tea.Model, msg tea.Msg) tea.Msg {
+	if size, ok := msg.(tea.WindowSizeMsg); ok {
+		r.Resize(size.Width, size.Height)
+	}
+	return msg
+}
+
+// Close flushes the recording and closes its file.
+func (r *Recorder) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.c == nil {
+		return r.err
+	}
+	err := r.w.Flush()
+	if cerr := r.c.Close(); err == nil {
+		err = cerr
+	}
+	r.c = nil
+	if r.err == nil {
+		r.err = err
+	}
+	return err
+}
+
+// event writes an event line. r.mu must be held.
+func (r *Recorder) event(kind, data string) {
+	if r.c == nil || r.err != nil || (r.max > 0 && r.size >= r.max) {
+		return
+	}
+	elapsed := math.Round(r.now().Sub(r.start).Seconds()*1e6) / 1e6
+	line, err := json.Marshal([]any{elapsed, kind, data})
+	if err != nil {
+		r.err = err
+		return
+	}
+	r.write(line)
+}
+
+// write writes a line of the recording. r.mu must be held, except from
+// NewRecorder.
+func (r *Recorder) write(line []byte) {
+	n, err := r.w.Write(append(line, '\n'))
+	r.size += int64(n)
+	if err != nil && r.err == nil {
+		r.err = err
+	}
+}
+
+// recordingWriter tees writes into a recording. Recording errors never
+// reach the writer's caller, so a full disk can't break the session.
+type recordingWriter struct {
+	w io.Writer
+	r *Recorder
+}
+
+// Write writes p to the terminal, then records what got written.
+func (w recordingWriter) Write(p []byte) (int, error) {
+	n, err := w.w.Write(p)
+	if n > 0 {
+		w.r.Output(p[:n])
+	}
+	return n, err
+}
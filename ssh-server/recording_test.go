@@ -0,0 +1,90 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/charmbracelet/log"
+)
+
+type nopWriteCloser struct{ io.Writer }
+
+func (nopWriteCloser) Close() error { return nil }
+
+func TestRecorder(t *testing.T) {
+	var buf bytes.Buffer
+	r, err := NewRecorder(nopWriteCloser{&buf}, RecordHeader{Width: 80, Height: 24}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := r.start
+	r.now = func() time.Time { return now }
+
+	// "é" split across writes is recorded whole, in the later event
+	now = now.Add(1500 * time.Millisecond)
+	w := r.Writer(io.Discard)
+	w.Write([]byte("caf\xc3"))
+	w.Write([]byte("\xa9\x1b[0m"))
+	now = now.Add(time.Second)
+	r.Resize(100, 30)
+	if err := r.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	var header RecordHeader
+	if err := json.Unmarshal([]byte(lines[0]), &header); err != nil || header.Version != 2 || header.Width != 80 {
+		t.Fatalf("header = %s (%v), want version 2 at 80 columns", lines[0], err)
+	}
+	want := []string{`[1.5,"o","caf"]`, `[1.5,"o","é\u001b[0m"]`, `[2.5,"r","100x30"]`}
+	if got := lines[1:]; strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("events:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+
+	// Past the size limit, output is dropped
+	buf.Reset()
+	r, _ = NewRecorder(nopWriteCloser{&buf}, RecordHeader{}, 64)
+	for range 10 {
+		r.Output([]byte("0123456789"))
+	}
+	r.Close()
+	if n := strings.Count(buf.String(), "\n"); n >= 5 {
+		t.Errorf("%d lines recorded with a 64 byte limit", n)
+	}
+}
+
+func TestRecordingsPrune(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Now()
+	for i, age := range []time.Duration{30 * 24 * time.Hour, 3 * time.Hour, 2 * time.Hour, time.Hour} {
+		path := filepath.Join(dir, string(rune('a'+i))+".cast")
+		if err := os.WriteFile(path, nil, 0o600); err != nil {
+			t.Fatal(err)
+		}
+		os.Chtimes(path, now.Add(-age), now.Add(-age))
+	}
+	os.WriteFile(filepath.Join(dir, "notes.txt"), nil, 0o600)
+
+	r := NewRecordings(RecordConfig{Mode: RecordAll, Dir: dir, Keep: 7 * 24 * time.Hour, MaxFiles: 3}, log.New(io.Discard))
+	rec, err := r.Start("id", TransportSSH, 80, 24, "xterm")
+	if err != nil {
+		t.Fatal(err)
+	}
+	rec.Close()
+
+	// a is too old and b the oldest once the new one needs room
+	var names []string
+	entries, _ := os.ReadDir(dir)
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+	if len(names) != 4 || !strings.HasSuffix(names[0], "-ssh-id.cast") || names[1] != "c.cast" || names[2] != "d.cast" || names[3] != "notes.txt" {
+		t.Errorf("left %v, want the new recording, c, d and notes.txt", names)
+	}
+}
@@ -0,0 +1,393 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"unicode"
+
+	"github.com/charmbracelet/log"
+)
+
+// VCard is a vCard 4.0 (RFC 6350) contact generated from the portfolio.
+type VCard struct {
+	Name     string `json:"name" yaml:"name"`
+	Title    string `json:"title" yaml:"title"`
+	Location string `json:"location,omitempty" yaml:"location,omitempty"`
+	Email    string `json:"email,omitempty" yaml:"email,omitempty"`
+	URL      string `json:"url,omitempty" yaml:"url,omitempty"`
+	Note     string `json:"note,omitempty" yaml:"note,omitempty"`
+	Profiles []Link `json:"profiles,omitempty" yaml:"profiles,omitempty"`
+}
+
+// NewVCard builds a contact card from the profile and links.
+func NewVCard(c *Content) VCard {
+	card := VCard{
+		Name:     c.Profile.Name,
+		Title:    c.Profile.Role,
+		Location: c.Profile.Location,
+		Note:     strings.Join(c.Profile.Bio, "\n\n"),
+	}
+	for _, link := range c.Links {
+		switch link.ID {
+		case "email":
+			card.Email = link.URL
+		case "website":
+			card.URL = markdownURL(link.URL)
+		default:
+			card.Profiles = append(card.Profiles, link)
+		}
+	}
+	return card
+}
+
+// String encodes the card in vCard 4.0 text format.
+func (v VCard) String() string {
+	var lines []string
+	add := func(line string) { lines = append(lines, foldVCardLine(line)) }
+
+	add("BEGIN:VCARD")
+	add("VERSION:4.0")
+	add("FN:" + vcardEscape(v.Name))
+	family, given := splitName(v.Name)
+	add("N:" + vcardEscape(family) + ";" + vcardEscape(given) + ";;;")
+	if v.Title != "" {
+		add("TITLE:" + vcardEscape(v.Title))
+	}
+	if v.Location != "" {
+		locality, region, _ := strings.Cut(v.Location, ",")
+		add("ADR;TYPE=work:;;;" + vcardEscape(strings.TrimSpace(locality)) + ";" + vcardEscape(strings.TrimSpace(region)) + ";;")
+	}
+	if v.Email != "" {
+		add("EMAIL;TYPE=work:" + vcardEscape(v.Email))
+	}
+	if v.URL != "" {
+		add("URL;TYPE=work:" + v.URL)
+	}
+	for _, p := range v.Profiles {
+		add("X-SOCIALPROFILE;TYPE=" + p.ID + ":" + markdownURL(p.URL))
+	}
+	if v.Note != "" {
+		add("NOTE:" + vcardEscape(v.Note))
+	}
+	add("END:VCARD")
+
+	// vCard requires CRLF line endings
+	return strings.Join(lines, "\r\n") + "\r\n"
+}
+
+// Styled renders the card with a download hint for the TUI.
+func (v VCard) Styled(st *Styles, t *Catalog) string {
+	var sb strings.Builder
+
+	sb.WriteString(st.Header(strings.ToUpper(t.T("vcard.heading"))))
+	sb.WriteString("\n\n")
+	sb.WriteString(st.Value(strings.ReplaceAll(v.String(), "\r\n", "\n")))
+	sb.WriteString("\n")
+	sb.WriteString(st.Dim(t.T("vcard.save")))
+
+	return sb.String()
+}
+
+// Plain returns the raw vCard, suitable for redirecting to a .vcf file.
+func (v VCard) Plain(*Catalog) string {
+	return v.String()
+}
+
+// Accessible reads the card out field by field rather than as vCard text.
+func (v VCard) Accessible(t *Catalog) string {
+	var sb strings.Builder
+
+	sb.WriteString(strings.ToUpper(t.T("vcard.heading")) + "\n\n")
+	sb.WriteString(t.T("field.name") + " " + v.Name + "\n")
+	sb.WriteString(t.T("field.role") + " " + v.Title + "\n")
+	if v.Location != "" {
+		sb.WriteString(t.T("field.location") + " " + v.Location + "\n")
+	}
+	if v.Email != "" {
+		sb.WriteString(t.T("field.email") + " " + v.Email + "\n")
+	}
+	if v.URL != "" {
+		sb.WriteString(t.T("field.website") + " " + v.URL + "\n")
+	}
+	for _, p := range v.Profiles {
+		sb.WriteString(p.Name + ": " + p.URL + "\n")
+	}
+	sb.WriteString("\n" + t.T("vcard.save") + "\n")
+
+	return sb.String()
+}
+
+// Markdown renders the card in a fenced code block.
+func (v VCard) Markdown(t *Catalog) string {
+	return "# " + t.T("vcard.heading") + "\n\n```vcard\n" + strings.ReplaceAll(v.String(), "\r\n", "\n") + "```\n"
+}
+
+// vcardEscape escapes text values as required by RFC 6350 section 3.4.
+func vcardEscape(s string) string {
+	r := strings.NewReplacer(`\`, `\\`, ",", `\,`, ";", `\;`, "\n", `\n`)
+	return r.Replace(s)
+}
+
+// foldVCardLine folds lines longer than 75 octets, continuing with a space,
+// without splitting multi-byte characters.
+func foldVCardLine(line string) string {
+	const limit = 75
+	if len(line) <= limit {
+		return line
+	}
+	var sb strings.Builder
+	width := 0
+	for _, r := range line {
+		n := len(string(r))
+		if width+n > limit {
+			sb.WriteString("\r\n ")
+			width = 1
+		}
+		sb.WriteRune(r)
+		width += n
+	}
+	return sb.String()
+}
+
+// splitName splits "Given Family" into family and given names.
+func splitName(name string) (family, given string) {
+	i := strings.LastIndex(name, " ")
+	if i < 0 {
+		return name, ""
+	}
+	return name[i+1:], name[:i]
+}
+
+// Resume is a JSON Resume (https://jsonresume.org/schema) document.
+type Resume struct {
+	Schema string        `json:"$schema" yaml:"$schema"`
+	Basics ResumeBasics  `json:"basics" yaml:"basics"`
+	Work   []ResumeWork  `json:"work" yaml:"work"`
+	Skills []ResumeSkill `json:"skills" yaml:"skills"`
+}
+
+// ResumeBasics is the "basics" section of a JSON Resume.
+type ResumeBasics struct {
+	Name     string          `json:"name" yaml:"name"`
+	Label    string          `json:"label" yaml:"label"`
+	Email    string          `json:"email,omitempty" yaml:"email,omitempty"`
+	URL      string          `json:"url,omitempty" yaml:"url,omitempty"`
+	Summary  string          `json:"summary,omitempty" yaml:"summary,omitempty"`
+	Location ResumeLocation  `json:"location" yaml:"location"`
+	Profiles []ResumeProfile `json:"profiles" yaml:"profiles"`
+}
+
+// ResumeLocation is the location of a JSON Resume's basics.
+type ResumeLocation struct {
+	City   string `json:"city,omitempty" yaml:"city,omitempty"`
+	Region string `json:"region,omitempty" yaml:"region,omitempty"`
+}
+
+// ResumeProfile is a social network profile.
+type ResumeProfile struct {
+	Network  string `json:"network" yaml:"network"`
+	Username string `json:"username,omitempty" yaml:"username,omitempty"`
+	URL      string `json:"url" yaml:"url"`
+}
+
+// ResumeWork is a position in a JSON Resume.
+type ResumeWork struct {
+	Name      string `json:"name" yaml:"name"`
+	Position  string `json:"position" yaml:"position"`
+	StartDate string `json:"startDate,omitempty" yaml:"startDate,omitempty"`
+	EndDate   string `json:"endDate,omitempty" yaml:"endDate,omitempty"`
+	URL       string `json:"url,omitempty" yaml:"url,omitempty"`
+	Summary   string `json:"summary,omitempty" yaml:"summary,omitempty"`
+}
+
+// ResumeSkill is a skill group in a JSON Resume.
+type ResumeSkill struct {
+	Name     string   `json:"name" yaml:"name"`
+	Level    string   `json:"level,omitempty" yaml:"level,omitempty"`
+	Keywords []string `json:"keywords" yaml:"keywords"`
+}
+
+// NewResume builds a JSON Resume from the portfolio content.
+func NewResume(c *Content) Resume {
+	card := NewVCard(c)
+	city, region, _ := strings.Cut(c.Profile.Location, ",")
+
+	r := Resume{
+		Schema: "https://raw.githubusercontent.com/jsonresume/resume-schema/v1.0.0/schema.json",
+		Basics: ResumeBasics{
+			Name:    c.Profile.Name,
+			Label:   c.Profile.Role,
+			Email:   card.Email,
+			URL:     card.URL,
+			Summary: strings.Join(c.Profile.Bio, "\n\n"),
+			Location: ResumeLocation{
+				City:   strings.TrimSpace(city),
+				Region: strings.TrimSpace(region),
+			},
+			Profiles: []ResumeProfile{},
+		},
+		Work:   []ResumeWork{},
+		Skills: []ResumeSkill{},
+	}
+	for _, p := range card.Profiles {
+		url := markdownURL(p.URL)
+		path := strings.TrimRight(url, "/")
+		r.Basics.Profiles = append(r.Basics.Profiles, ResumeProfile{
+			Network:  p.Name,
+			Username: path[strings.LastIndex(path, "/")+1:],
+			URL:      url,
+		})
+	}
+	for _, exp := range c.Experience {
+		start, end, _ := strings.Cut(exp.Period, "-")
+		end = strings.TrimSpace(end)
+		if end != "" && !unicode.IsDigit(rune(end[0])) {
+			// "Present", or its translation, marks an ongoing position
+			end = ""
+		}
+		work := ResumeWork{
+			Name:      exp.Company,
+			Position:  exp.Role,
+			StartDate: strings.TrimSpace(start),
+			EndDate:   end,
+			Summary:   exp.Description,
+		}
+		if exp.URL != "" {
+			work.URL = markdownURL(exp.URL)
+		}
+		r.Work = append(r.Work, work)
+	}
+	for _, cat := range c.Skills.Categories {
+		r.Skills = append(r.Skills, ResumeSkill{
+			Name:     cat.Name,
+			Level:    c.Skills.Proficiency.Level,
+			Keywords: cat.Technologies,
+		})
+	}
+	return r
+}
+
+// JSON returns the indented JSON Resume document.
+func (r Resume) JSON() string {
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		// Resume contains only strings and slices, so this cannot happen
+		return "{}"
+	}
+	return string(data) + "\n"
+}
+
+// Styled shows the document with a download hint for the TUI.
+func (r Resume) Styled(st *Styles, t *Catalog) string {
+	var sb strings.Builder
+
+	sb.WriteString(st.Header(strings.ToUpper(t.T("resume.heading"))))
+	sb.WriteString("\n\n")
+	sb.WriteString(st.Label(r.Basics.Name) + " - " + st.Value(r.Basics.Label) + "\n")
+	for _, w := range r.Work {
+		period := w.StartDate + " - "
+		if w.EndDate != "" {
+			period += w.EndDate
+		} else {
+			period += t.T("resume.present")
+		}
+		sb.WriteString("  " + st.Value(w.Position) + ", " + w.Name + " " + st.Dim(period) + "\n")
+	}
+	sb.WriteString("\n")
+	sb.WriteString(st.Dim(t.T("resume.download")))
+
+	return sb.String()
+}
+
+// Plain returns the JSON Resume document, so redirecting exec output to a
+// file yields a valid resume.json.
+func (r Resume) Plain(*Catalog) string {
+	return r.JSON()
+}
+
+// Accessible summarizes the resume as sentences rather than JSON.
+func (r Resume) Accessible(t *Catalog) string {
+	var sb strings.Builder
+
+	sb.WriteString(strings.ToUpper(t.T("resume.heading")) + "\n\n")
+	sb.WriteString(r.Basics.Name + ", " + r.Basics.Label + ".\n")
+	for _, w := range r.Work {
+		end := w.EndDate
+		if end == "" {
+			end = strings.ToLower(t.T("resume.present"))
+		}
+		sb.WriteString(t.T("resume.position", w.Position, w.Name, w.StartDate, end) + "\n")
+	}
+	sb.WriteString("\n" + t.T("resume.download") + "\n")
+
+	return sb.String()
+}
+
+// Markdown renders the resume as a Markdown CV.
+func (r Resume) Markdown(t *Catalog) string {
+	var sb strings.Builder
+
+	sb.WriteString("# " + r.Basics.Name + "\n\n")
+	sb.WriteString("**" + r.Basics.Label + "**")
+	if r.Basics.Location.City != "" {
+		sb.WriteString(" · " + r.Basics.Location.City)
+	}
+	sb.WriteString("\n\n")
+	if r.Basics.Summary != "" {
+		sb.WriteString(r.Basics.Summary + "\n\n")
+	}
+	sb.WriteString("## " + t.T("experience.heading") + "\n")
+	for _, w := range r.Work {
+		end := w.EndDate
+		if end == "" {
+			end = t.T("resume.present")
+		}
+		sb.WriteString("\n### " + w.Position + " - " + w.Name + "\n\n")
+		sb.WriteString("_" + w.StartDate + " - " + end + "_\n\n")
+		sb.WriteString(w.Summary + "\n")
+	}
+	sb.WriteString("\n## " + t.T("skills.heading") + "\n\n")
+	for _, s := range r.Skills {
+		sb.WriteString("- **" + s.Name + ":** " + strings.Join(s.Keywords, ", ") + "\n")
+	}
+
+	return sb.String()
+}
+
+// vcardDocument returns the contact card
+func vcardDocument(ctx *CommandContext) (Document, error) {
+	c, err := LoadContentFor(ctx.Catalog.Locale)
+	if err != nil {
+		return nil, err
+	}
+	return NewVCard(c), nil
+}
+
+// resumeDocument returns the JSON Resume
+func resumeDocument(ctx *CommandContext) (Document, error) {
+	c, err := LoadContentFor(ctx.Catalog.Locale)
+	if err != nil {
+		return nil, err
+	}
+	return NewResume(c), nil
+}
+
+// DownloadHandler serves a document as a file download, for /vcard.vcf and
+// /resume.json.
+func DownloadHandler(logger *log.Logger, filename, contentType string, build func(ctx *CommandContext) (Document, error)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		t := CatalogFor(HTTPLocale(r))
+		doc, err := build(&CommandContext{User: "guest", Format: FormatPlain, Catalog: t})
+		if err != nil {
+			logger.Error("Failed to build download", "file", filename, "error", err)
+			http.Error(w, "internal error", http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", contentType)
+		w.Header().Set("Content-Disposition", `attachment; filename="`+filename+`"`)
+		w.Header().Set("Access-Control-Allow-Origin", "*")
+		_, _ = w.Write([]byte(doc.Plain(t)))
+	}
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/charmbracelet/x/ansi"
+)
+
+// sanitizeLine makes a line typed by a visitor safe to show to others:
+// escape sequences, control characters and bidirectional overrides are
+// removed, whitespace runs become single spaces and the result is cut to
+// max runes.
+func sanitizeLine(s string, max int) string {
+	s = ansi.Strip(s)
+	s = strings.Map(func(r rune) rune {
+		switch {
+		case unicode.IsSpace(r):
+			return ' '
+		case unicode.IsControl(r), r >= '\u202a' && r <= '\u202e', r >= '\u2066' && r <= '\u2069':
+			return -1
+		}
+		return r
+	}, s)
+	s = strings.Join(strings.Fields(s), " ")
+	if r := []rune(s); len(r) > max {
+		s = strings.TrimSpace(string(r[:max]))
+	}
+	return s
+}
+
+// sanitizeText is sanitizeLine for text of several lines, such as a
+// message: each line is cleaned the same way, but line breaks are kept,
+// with runs of blank lines reduced to one.
+func sanitizeText(s string, max int) string {
+	s = strings.NewReplacer("\r\n", "\n", "\r", "\n").Replace(ansi.Strip(s))
+	var lines []string
+	blank := 0
+	for _, line := range strings.Split(s, "\n") {
+		line = sanitizeLine(line, max)
+		if line == "" {
+			blank++
+			continue
+		}
+		if len(lines) > 0 && blank > 0 {
+			lines = append(lines, "")
+		}
+		lines = append(lines, line)
+		blank = 0
+	}
+	s = strings.Join(lines, "\n")
+	if r := []rune(s); len(r) > max {
+		s = strings.TrimSpace(string(r[:max]))
+	}
+	return s
+}
+
+// profanity lists the words maskProfanity hides, with their common forms
+// spelled out so that innocent words sharing a prefix are left alone.
+var profanity = map[string]bool{
+	"arse": true, "arsehole": true, "asshole": true, "assholes": true,
+	"bastard": true, "bastards": true, "bitch": true, "bitches": true,
+	"bollocks": true, "bullshit": true, "cock": true, "cocks": true,
+	"cunt": true, "cunts": true, "dick": true, "dickhead": true,
+	"fuck": true, "fucked": true, "fucker": true, "fuckers": true, "fucking": true,
+	"motherfucker": true, "piss": true, "pissed": true, "prick": true,
+	"shit": true, "shits": true, "shitty": true, "slut": true, "twat": true,
+	"wanker": true, "whore": true,
+}
+
+// maskProfanity replaces the letters of listed words after the first with
+// asterisks, ignoring case: "shit" becomes "s***".
+func maskProfanity(s string) string {
+	r := []rune(s)
+	for i := 0; i < len(r); {
+		if !unicode.IsLetter(r[i]) {
+			i++
+			continue
+		}
+		j := i
+		for j < len(r) && unicode.IsLetter(r[j]) {
+			j++
+		}
+		if profanity[strings.ToLower(string(r[i:j]))] {
+			for k := i + 1; k < j; k++ {
+				r[k] = '*'
+			}
+		}
+		i = j
+	}
+	return string(r)
+}
@@ -0,0 +1,259 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/ssh"
+	"github.com/charmbracelet/wish"
+)
+
+// Transport identifies how a visitor is connected.
+type Transport string
+
+const (
+	TransportSSH       Transport = "ssh"
+	TransportWebSocket Transport = "ws"
+)
+
+// ErrDraining is returned by SessionRegistry.Register once shutdown started.
+var ErrDraining = errors.New("server is shutting down")
+
+// shutdownNotice is the catalog key of the notice shown to every connected
+// visitor when the server drains.
+const shutdownNotice = "notice.restart"
+
+// ShutdownNoticeMsg tells a running TUI that the server is about to go away.
+type ShutdownNoticeMsg struct {
+	Key string // catalog key of the notice, shown in the visitor's locale
+}
+
+// Session is a connected visitor, regardless of transport. The registry uses
+// it to deliver messages into the visitor's TUI and to force-close it.
+type Session struct {
+	ID        string
+	Transport Transport
+	User      string
+	Remote    string
+	Started   time.Time
+	Name      string // anonymous name shown to other visitors
+
+	mu       sync.Mutex
+	send     func(tea.Msg)
+	close    func()
+	view     string           // what the visitor is looking at, see SetView
+	registry *SessionRegistry // set by Register
+}
+
+// NewSession creates a session that is closed by calling closeFn.
+func NewSession(id string, transport Transport, user, remote string, closeFn func()) *Session {
+	return &Session{
+		ID:        id,
+		Transport: transport,
+		User:      user,
+		Remote:    remote,
+		Started:   time.Now(),
+		Name:      visitorName(id),
+		close:     closeFn,
+	}
+}
+
+// Host returns the address the visitor connects from, without the port.
+func (s *Session) Host() string {
+	host, _, err := net.SplitHostPort(s.Remote)
+	if err != nil {
+		return s.Remote
+	}
+	return host
+}
+
+// SetSender sets how messages reach the session's TUI. Sessions without a
+// sender (such as exec requests) silently drop messages, and aren't counted
+// as online.
+func (s *Session) SetSender(send func(tea.Msg)) {
+	s.mu.Lock()
+	joined := s.send == nil && send != nil
+	s.send = send
+	registry := s.registry
+	s.mu.Unlock()
+	if joined && registry != nil {
+		registry.Broadcast(PresenceMsg{})
+	}
+}
+
+// Send delivers msg to the session's TUI, if it has one.
+func (s *Session) Send(msg tea.Msg) {
+	s.mu.Lock()
+	send := s.send
+	s.mu.Unlock()
+	if send != nil {
+		send(msg)
+	}
+}
+
+// Registry returns the registry the session was registered with, nil for
+// none or a nil session.
+func (s *Session) Registry() *SessionRegistry {
+	if s == nil {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.registry
+}
+
+// Close forcibly disconnects the session.
+func (s *Session) Close() {
+	if s.close != nil {
+		s.close()
+	}
+}
+
+// SessionRegistry tracks every open session across SSH and WebSocket so that
+// shutdown can notify and wait for them.
+type SessionRegistry struct {
+	mu       sync.Mutex
+	sessions map[string]*Session
+	draining bool
+	idle     chan struct{}
+	chat     *ChatRoom
+}
+
+// NewSessionRegistry creates an empty registry.
+func NewSessionRegistry() *SessionRegistry {
+	return &SessionRegistry{
+		sessions: make(map[string]*Session),
+		idle:     make(chan struct{}),
+		chat:     NewChatRoom(),
+	}
+}
+
+// Chat returns the chat room shared by the sessions.
+func (r *SessionRegistry) Chat() *ChatRoom {
+	return r.chat
+}
+
+// Register adds a session. It fails with ErrDraining once Drain was called.
+func (r *SessionRegistry) Register(s *Session) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.draining {
+		return ErrDraining
+	}
+	r.sessions[s.ID] = s
+	s.mu.Lock()
+	s.registry = r
+	s.mu.Unlock()
+	return nil
+}
+
+// Unregister removes a session when it ends, telling the others when it
+// was online.
+func (r *SessionRegistry) Unregister(id string) {
+	r.mu.Lock()
+	s, ok := r.sessions[id]
+	if !ok {
+		r.mu.Unlock()
+		return
+	}
+	delete(r.sessions, id)
+	if r.draining && len(r.sessions) == 0 {
+		close(r.idle)
+	}
+	r.mu.Unlock()
+	r.chat.Leave(id)
+
+	s.mu.Lock()
+	online := s.send != nil
+	s.mu.Unlock()
+	if online {
+		r.Broadcast(PresenceMsg{})
+	}
+}
+
+// Len returns the number of open sessions.
+func (r *SessionRegistry) Len() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.sessions)
+}
+
+// Sessions returns a snapshot of the open sessions.
+func (r *SessionRegistry) Sessions() []*Session {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	sessions := make([]*Session, 0, len(r.sessions))
+	for _, s := range r.sessions {
+		sessions = append(sessions, s)
+	}
+	return sessions
+}
+
+// Broadcast sends msg to every open session. Each delivery runs in its own
+// goroutine so one slow client cannot hold up the others.
+func (r *SessionRegistry) Broadcast(msg tea.Msg) {
+	for _, s := range r.Sessions() {
+		go s.Send(msg)
+	}
+}
+
+// Drain stops accepting sessions, tells every visitor the server is going
+// away and waits for them to leave. Sessions still open when ctx expires are
+// closed forcibly and ctx's error is returned. notice is a catalog key, so
+// each visitor reads it in their own language.
+func (r *SessionRegistry) Drain(ctx context.Context, notice string) error {
+	r.mu.Lock()
+	if !r.draining {
+		r.draining = true
+		if len(r.sessions) == 0 {
+			close(r.idle)
+		}
+	}
+	r.mu.Unlock()
+
+	r.Broadcast(ShutdownNoticeMsg{Key: notice})
+
+	select {
+	case <-r.idle:
+		return nil
+	case <-ctx.Done():
+		for _, s := range r.Sessions() {
+			s.Close()
+		}
+		return ctx.Err()
+	}
+}
+
+type sessionContextKey struct{}
+
+// sessionFromContext returns the registry session attached to an SSH session
+// by sessionRegistryMiddleware.
+func sessionFromContext(s ssh.Session) *Session {
+	sess, _ := s.Context().Value(sessionContextKey{}).(*Session)
+	return sess
+}
+
+// sessionRegistryMiddleware registers every SSH session for its lifetime and
+// turns visitors away once the server is draining.
+func sessionRegistryMiddleware(registry *SessionRegistry) wish.Middleware {
+	return func(next ssh.Handler) ssh.Handler {
+		return func(s ssh.Session) {
+			sess := NewSession(SessionID(s), TransportSSH, s.User(), s.RemoteAddr().String(), func() {
+				_ = s.Close()
+			})
+			if err := registry.Register(sess); err != nil {
+				// Before the TUI there is no locale yet
+				wish.Fatalln(s, CatalogFor(LocaleEnglish).T(shutdownNotice))
+				return
+			}
+			defer registry.Unregister(sess.ID)
+
+			s.Context().SetValue(sessionContextKey{}, sess)
+			next(s)
+		}
+	}
+}
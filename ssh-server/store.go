@@ -0,0 +1,67 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// dataDir is where the server keeps what visitors leave behind, such as
+// guestbook entries. Mount a volume there to keep it across deploys.
+var dataDir = envOr("DATA_DIR", "data")
+
+// envOr returns the environment variable key, or def when it is unset.
+func envOr(key, def string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return def
+}
+
+// loadJSON decodes the JSON file name in the data directory into v. A
+// missing file leaves v alone, since nothing was stored yet.
+func loadJSON(name string, v interface{}) error {
+	data, err := os.ReadFile(filepath.Join(dataDir, name))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return &fs.PathError{Op: "decode", Path: filepath.Join(dataDir, name), Err: err}
+	}
+	return nil
+}
+
+// saveJSON writes v to the JSON file name in the data directory. The file
+// is replaced in one step, so a crash leaves either the old or the new
+// version.
+func saveJSON(name string, v interface{}) error {
+	if err := os.MkdirAll(dataDir, 0o700); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(dataDir, name+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(append(data, '\n')); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), filepath.Join(dataDir, name))
+}
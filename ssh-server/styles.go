@@ -1,113 +1,340 @@
 package main
 
-import "github.com/charmbracelet/lipgloss"
-
-// Cyberpunk color scheme matching the website
-var (
-	// Primary colors
-	CyanColor   = lipgloss.Color("#22e9d8")
-	PinkColor   = lipgloss.Color("#e34880")
-	PurpleColor = lipgloss.Color("#8b5cf6")
-	GreenColor  = lipgloss.Color("#10b981")
-	YellowColor = lipgloss.Color("#fbbf24")
-	RedColor    = lipgloss.Color("#ef4444")
-
-	// Background/Text colors
-	DarkBg    = lipgloss.Color("#0a0a0f")
-	LightText = lipgloss.Color("#f9fafb")
-	DimText   = lipgloss.Color("#9ca3af")
+import (
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/muesli/termenv"
 )
 
-// Style definitions
-var (
+// Styles are the lipgloss styles derived from a Theme. Every session owns its
+// own instance, bound to the session's renderer, so visitors can use
+// different themes and color profiles at the same time.
+type Styles struct {
+	Theme Theme
+
+	// Accessible styles carry no color, borders or padding, and commands
+	// avoid decorative glyphs, so output reads as linear text for screen
+	// readers and braille displays.
+	Accessible bool
+
+	// Hyperlinks turns links into OSC 8 hyperlinks, for terminals that
+	// support them.
+	Hyperlinks bool
+
+	renderer *lipgloss.Renderer
+
 	// Title styles
-	TitleStyle = lipgloss.NewStyle().
-			Foreground(CyanColor).
-			Bold(true).
-			Padding(1, 2)
+	Title   lipgloss.Style
+	Banner  lipgloss.Style
+	Tagline lipgloss.Style
 
 	// Menu styles
-	MenuTitleStyle = lipgloss.NewStyle().
-			Foreground(CyanColor).
+	MenuTitle    lipgloss.Style
+	SelectedItem lipgloss.Style
+	NormalItem   lipgloss.Style
+	HoverItem    lipgloss.Style
+
+	// Links under the mouse pointer, and the link picked with a click or Tab
+	LinkHover    lipgloss.Style
+	LinkSelected lipgloss.Style
+
+	// Content styles
+	HeaderBox lipgloss.Style
+	Content   lipgloss.Style
+	Emphasis  lipgloss.Style
+	LabelText lipgloss.Style
+	ValueText lipgloss.Style
+	DimText   lipgloss.Style
+	Error     lipgloss.Style
+
+	// Help text
+	Help lipgloss.Style
+
+	// Server notices (shutdown, maintenance)
+	Notice lipgloss.Style
+
+	// Command prompt
+	Prompt lipgloss.Style
+
+	// Box drawing
+	BoxFrame lipgloss.Style
+
+	// Text fields of forms, and the one being typed in
+	FormField        lipgloss.Style
+	FormFieldFocused lipgloss.Style
+
+	// Markdown content: headings, list bullets, block quotes, code, link
+	// text and the colors of highlighted code
+	Heading     lipgloss.Style
+	Bullet      lipgloss.Style
+	Quote       lipgloss.Style
+	Code        lipgloss.Style
+	LinkText    lipgloss.Style
+	CodeKeyword lipgloss.Style
+	CodeString  lipgloss.Style
+	CodeComment lipgloss.Style
+	CodeNumber  lipgloss.Style
+
+	// ASCII art table styles
+	TableBorder lipgloss.Style
+	TableHeader lipgloss.Style
+	TableCell   lipgloss.Style
+}
+
+// NewStyles derives the style set for a theme, rendered by r. A nil renderer
+// uses lipgloss's default renderer.
+func NewStyles(t Theme, r *lipgloss.Renderer) *Styles {
+	if r == nil {
+		r = lipgloss.DefaultRenderer()
+	}
+	return &Styles{
+		Theme:    t,
+		renderer: r,
+
+		Title: r.NewStyle().
+			Foreground(t.Primary).
+			Bold(true).
+			Padding(1, 2),
+
+		Banner: r.NewStyle().
+			Foreground(t.Primary).
+			Bold(true),
+
+		Tagline: r.NewStyle().
+			Foreground(t.Accent).
+			Bold(true),
+
+		MenuTitle: r.NewStyle().
+			Foreground(t.Primary).
 			Bold(true).
 			Border(lipgloss.RoundedBorder()).
-			BorderForeground(CyanColor).
-			Padding(0, 1)
+			BorderForeground(t.Primary).
+			Padding(0, 1),
 
-	SelectedItemStyle = lipgloss.NewStyle().
-				Foreground(PinkColor).
-				Bold(true).
-				PaddingLeft(2)
+		SelectedItem: r.NewStyle().
+			Foreground(t.Accent).
+			Bold(true).
+			PaddingLeft(2),
 
-	NormalItemStyle = lipgloss.NewStyle().
-			Foreground(LightText).
-			PaddingLeft(4)
+		NormalItem: r.NewStyle().
+			Foreground(t.Text).
+			PaddingLeft(4),
 
-	// Content styles
-	HeaderStyle = lipgloss.NewStyle().
-			Foreground(CyanColor).
+		HoverItem: r.NewStyle().
+			Foreground(t.Primary).
+			PaddingLeft(4),
+
+		LinkHover: r.NewStyle().
+			Underline(true),
+
+		LinkSelected: r.NewStyle().
+			Reverse(true),
+
+		HeaderBox: r.NewStyle().
+			Foreground(t.Primary).
 			Bold(true).
 			Border(lipgloss.DoubleBorder()).
-			BorderForeground(CyanColor).
+			BorderForeground(t.Primary).
 			Padding(0, 2).
-			Width(60)
+			Width(60),
 
-	ContentStyle = lipgloss.NewStyle().
-			Foreground(LightText).
-			Padding(1, 2)
+		Content: r.NewStyle().
+			Foreground(t.Text).
+			Padding(1, 2),
 
-	LabelStyle = lipgloss.NewStyle().
-			Foreground(GreenColor).
-			Bold(true)
+		Emphasis: r.NewStyle().
+			Foreground(t.Secondary).
+			Padding(1, 2),
 
-	ValueStyle = lipgloss.NewStyle().
-			Foreground(YellowColor)
+		LabelText: r.NewStyle().
+			Foreground(t.Success).
+			Bold(true),
 
-	DimStyle = lipgloss.NewStyle().
-			Foreground(DimText).
-			Italic(true)
+		ValueText: r.NewStyle().
+			Foreground(t.Warning),
 
-	// Help text
-	HelpStyle = lipgloss.NewStyle().
-			Foreground(DimText).
-			Padding(1, 2)
+		DimText: r.NewStyle().
+			Foreground(t.Muted).
+			Italic(true),
 
-	// Box drawing
-	BoxStyle = lipgloss.NewStyle().
+		Error: r.NewStyle().
+			Foreground(t.Danger).
+			Bold(true),
+
+		Help: r.NewStyle().
+			Foreground(t.Muted).
+			Padding(1, 2),
+
+		Notice: r.NewStyle().
+			Foreground(t.Background).
+			Background(t.Warning).
+			Bold(true).
+			Padding(0, 1),
+
+		Prompt: r.NewStyle().
+			Foreground(t.Accent).
+			Bold(true),
+
+		BoxFrame: r.NewStyle().
 			Border(lipgloss.RoundedBorder()).
-			BorderForeground(PurpleColor).
+			BorderForeground(t.Secondary).
 			Padding(1, 2).
-			Width(60)
+			Width(60),
 
-	// ASCII art table styles
-	TableBorderStyle = lipgloss.NewStyle().
-				Foreground(YellowColor)
+		FormField: r.NewStyle().
+			Foreground(t.Text).
+			Border(lipgloss.RoundedBorder()).
+			BorderForeground(t.Muted).
+			Padding(0, 1),
 
-	TableHeaderStyle = lipgloss.NewStyle().
-				Foreground(GreenColor).
-				Bold(true)
+		FormFieldFocused: r.NewStyle().
+			Foreground(t.Text).
+			Border(lipgloss.RoundedBorder()).
+			BorderForeground(t.Accent).
+			Padding(0, 1),
 
-	TableCellStyle = lipgloss.NewStyle().
-			Foreground(LightText)
-)
+		Heading: r.NewStyle().
+			Foreground(t.Primary).
+			Bold(true),
+
+		Bullet: r.NewStyle().
+			Foreground(t.Accent),
+
+		Quote: r.NewStyle().
+			Foreground(t.Secondary).
+			Italic(true),
+
+		Code: r.NewStyle().
+			Foreground(t.Warning),
+
+		// No underline: lipgloss underlines rune by rune, which multiplies
+		// the escape codes of every word
+		LinkText: r.NewStyle().
+			Foreground(t.Primary),
+
+		CodeKeyword: r.NewStyle().
+			Foreground(t.Primary).
+			Bold(true),
+
+		CodeString: r.NewStyle().
+			Foreground(t.Success),
+
+		CodeComment: r.NewStyle().
+			Foreground(t.Muted).
+			Italic(true),
+
+		CodeNumber: r.NewStyle().
+			Foreground(t.Warning),
+
+		TableBorder: r.NewStyle().
+			Foreground(t.Warning),
+
+		TableHeader: r.NewStyle().
+			Foreground(t.Success).
+			Bold(true),
+
+		TableCell: r.NewStyle().
+			Foreground(t.Text),
+	}
+}
+
+// accessibleStyles returns the undecorated style set used in accessible
+// mode. The theme is kept so switching back restores it.
+func accessibleStyles(t Theme, r *lipgloss.Renderer) *Styles {
+	if r == nil {
+		r = lipgloss.DefaultRenderer()
+	}
+	st := &Styles{Theme: t, renderer: r, Accessible: true}
+	for _, style := range []*lipgloss.Style{
+		&st.Title, &st.Banner, &st.Tagline,
+		&st.MenuTitle, &st.SelectedItem, &st.NormalItem, &st.HoverItem,
+		&st.LinkHover, &st.LinkSelected,
+		&st.HeaderBox, &st.Content, &st.Emphasis, &st.LabelText, &st.ValueText, &st.DimText, &st.Error,
+		&st.Help, &st.Notice, &st.Prompt, &st.BoxFrame,
+		&st.FormField, &st.FormFieldFocused,
+		&st.Heading, &st.Bullet, &st.Quote, &st.Code, &st.LinkText,
+		&st.CodeKeyword, &st.CodeString, &st.CodeComment, &st.CodeNumber,
+		&st.TableBorder, &st.TableHeader, &st.TableCell,
+	} {
+		*style = r.NewStyle()
+	}
+	return st
+}
+
+// WithTheme returns styles for theme t on the same renderer.
+func (s *Styles) WithTheme(t Theme) *Styles {
+	var st *Styles
+	if s.Accessible {
+		st = accessibleStyles(t, s.renderer)
+	} else {
+		st = NewStyles(t, s.renderer)
+	}
+	st.Hyperlinks = s.Hyperlinks
+	return st
+}
+
+// WithAccessible returns the same theme's styles with accessible mode
+// switched on or off.
+func (s *Styles) WithAccessible(on bool) *Styles {
+	var st *Styles
+	if on {
+		st = accessibleStyles(s.Theme, s.renderer)
+	} else {
+		st = NewStyles(s.Theme, s.renderer)
+	}
+	st.Hyperlinks = s.Hyperlinks
+	return st
+}
+
+// WithHyperlinks returns a copy of the styles with OSC 8 hyperlinks
+// switched on or off.
+func (s *Styles) WithHyperlinks(on bool) *Styles {
+	st := *s
+	st.Hyperlinks = on
+	return &st
+}
+
+// ColorProfile returns the color profile the session renders with.
+func (s *Styles) ColorProfile() termenv.Profile {
+	return s.renderer.ColorProfile()
+}
+
+// NewStyle returns an empty style bound to the session's renderer, for
+// one-off styling.
+func (s *Styles) NewStyle() lipgloss.Style {
+	return s.renderer.NewStyle()
+}
 
 // Helper functions for common formatting
-func Header(text string) string {
-	return HeaderStyle.Render(text)
+func (s *Styles) Header(text string) string {
+	return s.HeaderBox.Render(text)
+}
+
+func (s *Styles) Label(text string) string {
+	return s.LabelText.Render(text)
 }
 
-func Label(text string) string {
-	return LabelStyle.Render(text)
+func (s *Styles) Value(text string) string {
+	return s.ValueText.Render(text)
 }
 
-func Value(text string) string {
-	return ValueStyle.Render(text)
+func (s *Styles) Dim(text string) string {
+	return s.DimText.Render(text)
 }
 
-func Dim(text string) string {
-	return DimStyle.Render(text)
+func (s *Styles) Box(content string) string {
+	return s.BoxFrame.Render(content)
 }
 
-func Box(content string) string {
-	return BoxStyle.Render(content)
+// Link shows text linking to url, which may be scheme-less as in the
+// content files. Terminals with hyperlinks get text as an OSC 8 link; the
+// rest get plain text, followed by the URL when the text doesn't show it.
+func (s *Styles) Link(text, url string) string {
+	if s.Hyperlinks && !s.Accessible {
+		return Hyperlink(markdownURL(url), text)
+	}
+	if ansi.Strip(text) == url {
+		return text
+	}
+	return text + " " + s.Dim(url)
 }
@@ -0,0 +1,184 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Theme is a named color scheme. Styles are derived from it with NewStyles.
+type Theme struct {
+	Name        string
+	Description string
+
+	// Primary colors
+	Primary   lipgloss.TerminalColor // titles, borders
+	Accent    lipgloss.TerminalColor // selection, highlights
+	Secondary lipgloss.TerminalColor // emphasis, boxes
+	Success   lipgloss.TerminalColor // labels
+	Warning   lipgloss.TerminalColor // values, tables, notices
+	Danger    lipgloss.TerminalColor // errors
+
+	// Background/Text colors
+	Background lipgloss.TerminalColor
+	Text       lipgloss.TerminalColor
+	Muted      lipgloss.TerminalColor
+}
+
+// themeColor defines a theme color by its hex value, which lipgloss
+// downsamples for 256-color terminals, plus a hand-picked ANSI color for
+// 16-color terminals where automatic matching looks poor.
+func themeColor(hex, ansi string) lipgloss.TerminalColor {
+	return lipgloss.CompleteColor{TrueColor: hex, ANSI256: hex, ANSI: ansi}
+}
+
+// DefaultTheme is used for new sessions.
+const DefaultTheme = "cyberpunk"
+
+// Themes lists the built-in themes in the order they are offered.
+var Themes = []Theme{
+	{
+		// Cyberpunk color scheme matching the website
+		Name:        "cyberpunk",
+		Description: "Neon cyan and pink, matching the website",
+		Primary:     themeColor("#22e9d8", "14"),
+		Accent:      themeColor("#e34880", "13"),
+		Secondary:   themeColor("#8b5cf6", "5"),
+		Success:     themeColor("#10b981", "2"),
+		Warning:     themeColor("#fbbf24", "11"),
+		Danger:      themeColor("#ef4444", "9"),
+		Background:  themeColor("#0a0a0f", "0"),
+		Text:        themeColor("#f9fafb", "15"),
+		Muted:       themeColor("#9ca3af", "8"),
+	},
+	{
+		Name:        "solarized",
+		Description: "Solarized dark",
+		Primary:     themeColor("#268bd2", "4"),
+		Accent:      themeColor("#d33682", "5"),
+		Secondary:   themeColor("#6c71c4", "13"),
+		Success:     themeColor("#859900", "2"),
+		Warning:     themeColor("#b58900", "3"),
+		Danger:      themeColor("#dc322f", "1"),
+		Background:  themeColor("#002b36", "0"),
+		Text:        themeColor("#93a1a1", "7"),
+		Muted:       themeColor("#586e75", "8"),
+	},
+	{
+		Name:        "monochrome",
+		Description: "Shades of grey",
+		Primary:     themeColor("#ffffff", "15"),
+		Accent:      themeColor("#ffffff", "15"),
+		Secondary:   themeColor("#d0d0d0", "7"),
+		Success:     themeColor("#e0e0e0", "7"),
+		Warning:     themeColor("#bcbcbc", "7"),
+		Danger:      themeColor("#ffffff", "15"),
+		Background:  themeColor("#000000", "0"),
+		Text:        themeColor("#d0d0d0", "7"),
+		Muted:       themeColor("#808080", "8"),
+	},
+	{
+		Name:        "high-contrast",
+		Description: "Maximum contrast for low vision",
+		Primary:     themeColor("#00ffff", "14"),
+		Accent:      themeColor("#ffff00", "11"),
+		Secondary:   themeColor("#ffffff", "15"),
+		Success:     themeColor("#00ff00", "10"),
+		Warning:     themeColor("#ffff00", "11"),
+		Danger:      themeColor("#ff0000", "9"),
+		Background:  themeColor("#000000", "0"),
+		Text:        themeColor("#ffffff", "15"),
+		Muted:       themeColor("#ffffff", "15"),
+	},
+	{
+		// Matches the amber phosphor of the website's CRT shader
+		Name:        "amber",
+		Description: "Amber CRT phosphor",
+		Primary:     themeColor("#ff9933", "11"),
+		Accent:      themeColor("#ffb366", "11"),
+		Secondary:   themeColor("#ff8800", "3"),
+		Success:     themeColor("#ffaa44", "11"),
+		Warning:     themeColor("#ffcc80", "11"),
+		Danger:      themeColor("#ff5500", "9"),
+		Background:  themeColor("#1a0f00", "0"),
+		Text:        themeColor("#ff9933", "3"),
+		Muted:       themeColor("#cc6600", "3"),
+	},
+}
+
+// FindTheme looks up a built-in theme by name.
+func FindTheme(name string) (Theme, bool) {
+	for _, t := range Themes {
+		if strings.EqualFold(t.Name, name) {
+			return t, true
+		}
+	}
+	return Theme{}, false
+}
+
+// MustTheme returns the named built-in theme, falling back to the default.
+func MustTheme(name string) Theme {
+	if t, ok := FindTheme(name); ok {
+		return t
+	}
+	t, _ := FindTheme(DefaultTheme)
+	return t
+}
+
+// themeCommand lists the built-in themes, or switches the session's theme
+// when given a name (`theme amber`).
+func themeCommand(ctx *CommandContext) (string, error) {
+	st, t := ctx.Styles, ctx.Catalog
+	if len(ctx.Args) > 1 {
+		return "", &UsageError{t.T("theme.usage")}
+	}
+	if len(ctx.Args) == 1 {
+		th, ok := FindTheme(ctx.Args[0])
+		if !ok {
+			return "", &UsageError{t.T("theme.unknown", ctx.Args[0])}
+		}
+		ctx.SetTheme(th)
+		st = ctx.Styles
+	}
+
+	describe := func(th Theme) string {
+		if key := "themes." + th.Name; t.Has(key) {
+			return t.T(key)
+		}
+		return th.Description
+	}
+
+	var sb strings.Builder
+
+	sb.WriteString(st.Header(strings.ToUpper(t.T("theme.heading"))))
+	sb.WriteString("\n\n")
+	if st.Accessible {
+		for _, th := range Themes {
+			current := ""
+			if th.Name == st.Theme.Name {
+				current = t.T("list.current")
+			}
+			sb.WriteString(fmt.Sprintf("%s%s: %s\n", th.Name, current, describe(th)))
+		}
+		sb.WriteString("\n" + t.T("theme.switch_accessible"))
+		return sb.String(), nil
+	}
+	for _, th := range Themes {
+		swatch := ""
+		for _, c := range []lipgloss.TerminalColor{th.Primary, th.Accent, th.Secondary, th.Success, th.Warning} {
+			swatch += st.NewStyle().Foreground(c).Render("█")
+		}
+		marker := "  "
+		name := st.Value(padRight(th.Name, 14))
+		if th.Name == st.Theme.Name {
+			marker = st.SelectedItem.UnsetPaddingLeft().Render("▸ ")
+			name = st.Label(padRight(th.Name, 14))
+		}
+		sb.WriteString(fmt.Sprintf("%s%s %s  %s\n", marker, name, swatch, describe(th)))
+	}
+	sb.WriteString("\n")
+	sb.WriteString(st.Dim(t.T("theme.switch")))
+
+	return sb.String(), nil
+}
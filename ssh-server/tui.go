@@ -2,10 +2,12 @@ package main
 
 import (
 	"fmt"
+	"strconv"
 	"strings"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
 )
 
 // ViewMode represents the current view state
@@ -14,62 +16,210 @@ type ViewMode int
 const (
 	MenuMode ViewMode = iota
 	ContentMode
+	IntroMode // the boot sequence played when the TUI opens
+	FormMode  // a form a command asked for, such as the contact message
+	ChatMode  // the chat room
 )
 
 // Model represents the Bubble Tea application model
 type Model struct {
-	commands      []Command
-	cursor        int
-	selectedCmd   *Command
-	mode          ViewMode
-	width         int
-	height        int
-	welcomeShown  bool
+	commands     []Command
+	menu         *Menu
+	selectedCmd  *Command
+	mode         ViewMode
+	width        int
+	height       int
+	welcomeShown bool   // the welcome banner was left behind for a command
+	frame        int    // frames of the intro played so far
+	notice       string // catalog key of a server notice
+	user         string
+	styles       *Styles
+	catalog      *Catalog
+	output       string   // output of the last command run, shown in ContentMode
+	prompting    bool     // the ':' command prompt is open
+	input        string   // text typed at the prompt
+	completions  []string // candidates offered by the last Tab at the prompt
+	zones        *Zones   // clickable parts of the last rendered view
+	hover        string   // ID of the zone under the mouse pointer
+	scroll       int      // first line of the output shown in ContentMode
+	links        []Link   // links that appear in the output
+	link         int      // index into links of the picked link, -1 for none
+	copied       bool     // the picked link was just copied
+	clipboard    Clipboard
+	dir          string            // working directory of the file commands, "" for home
+	key          string            // fingerprint of the visitor's SSH key, "" for none
+	args         []string          // arguments the selected command ran with
+	choices      []Choice          // entries of the output that open another command line
+	choice       int               // index into choices of the picked entry, -1 for none
+	history      []visit           // content views left by opening a choice, latest last
+	form         *Form             // form being filled in, in FormMode
+	formField    int               // index of the focused field, len(form.Fields) for the submit button
+	formErrors   map[string]string // validation errors by field name
+	formStatus   string            // why sending the form failed
+	session      *Session          // the visitor's connection, nil when not tracked
+	online       int               // visitors browsing, updated by PresenceMsg
+	chat         *ChatRoom         // the room joined, in ChatMode
+	chatNick     string            // the visitor's nickname in the room
+	chatLines    []ChatLine        // the room's scrollback, updated by ChatMsg
+	chatInput    string            // message being typed
+	chatScroll   int               // wrapped lines scrolled back from the latest
+	chatStatus   string            // why the last message wasn't sent
+	recording    bool              // the session is being recorded
+}
+
+// visit is a content view to return to with ESC after opening a choice.
+type visit struct {
+	cmd    Command
+	args   []string
+	choice int
+	scroll int
+}
+
+// AccessibilityMsg switches accessible mode on or off, for clients that
+// announce a screen reader after connecting.
+type AccessibilityMsg struct {
+	Enabled bool
+}
+
+// LocaleMsg switches the session's language, for clients that report it
+// after connecting.
+type LocaleMsg struct {
+	Locale Locale
 }
 
 // NewModel creates a new TUI model
 func NewModel() Model {
+	commands := GetAllCommands()
 	return Model{
-		commands:     GetAllCommands(),
-		cursor:       0,
+		commands:     commands,
+		menu:         NewMenu(commands),
 		mode:         MenuMode,
 		welcomeShown: false,
+		styles:       NewStyles(MustTheme(DefaultTheme), nil),
+		catalog:      CatalogFor(DefaultLocale),
+		zones:        &Zones{},
+		link:         -1,
+		choice:       -1,
 	}
 }
 
 // Init initializes the model
 func (m Model) Init() tea.Cmd {
+	if m.mode == IntroMode {
+		return introTick()
+	}
 	return nil
 }
 
-// Update handles messages and updates the model
+// Update handles messages and updates the model, then tells the session
+// what the visitor is looking at for `who`.
 func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	next, cmd := m.update(msg)
+	if m, ok := next.(Model); ok && m.session != nil {
+		m.session.SetView(m.viewName())
+	}
+	return next, cmd
+}
+
+// viewName names the current view: the intro, the menu or the command
+// whose output or form is shown. Arguments are left out, as they may be
+// private.
+func (m Model) viewName() string {
+	switch {
+	case m.mode == IntroMode:
+		return "intro"
+	case m.mode == MenuMode || m.selectedCmd == nil:
+		return "menu"
+	default:
+		return m.selectedCmd.Name
+	}
+}
+
+func (m Model) update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.WindowSizeMsg:
 		m.width = msg.Width
 		m.height = msg.Height
+		m.scrollBy(0)
+		return m, nil
+
+	case ShutdownNoticeMsg:
+		m.notice = msg.Key
 		return m, nil
 
+	case ChatMsg:
+		if m.chat != nil {
+			m.chatLines = m.chat.Lines()
+		}
+		return m, nil
+
+	case PresenceMsg:
+		if r := m.session.Registry(); r != nil {
+			m.online = len(r.Online())
+		}
+		return m, nil
+
+	case AccessibilityMsg:
+		m.styles = m.styles.WithAccessible(msg.Enabled)
+		if msg.Enabled && m.mode == IntroMode {
+			m.mode = MenuMode
+		}
+		return m, nil
+
+	case introTickMsg:
+		return m.updateIntro()
+
+	case LocaleMsg:
+		m.catalog = CatalogFor(msg.Locale)
+		return m, nil
+
+	case tea.MouseMsg:
+		return m.updateMouse(msg)
+
 	case tea.KeyMsg:
-		// Check for rune-based keys first (h, q) to ensure they work correctly
+		// Any key but ctrl+c skips the intro
+		if m.mode == IntroMode && msg.Type != tea.KeyCtrlC {
+			m.mode = MenuMode
+			return m, nil
+		}
+
+		// Forms, the chat room and the command prompt capture all typing
+		// while open
+		if m.mode == FormMode {
+			return m.updateForm(msg)
+		}
+		if m.mode == ChatMode {
+			return m.updateChat(msg)
+		}
+		if m.prompting {
+			return m.updatePrompt(msg)
+		}
+
+		// Check for rune-based keys first (:, q, shortcuts) to ensure they work correctly
 		if msg.Type == tea.KeyRunes && len(msg.Runes) == 1 {
-			switch msg.Runes[0] {
-			case 'h':
-				// Quick help shortcut
-				if m.mode == MenuMode {
-					for i, cmd := range m.commands {
-						if cmd.Name == "help" {
-							m.cursor = i
-							m.selectedCmd = &m.commands[i]
-							m.mode = ContentMode
-							break
-						}
-					}
-				}
+			switch r := msg.Runes[0]; r {
+			case ':':
+				// Open the command prompt to run commands with arguments
+				m.prompting = true
+				m.input = ""
 				return m, nil
 			case 'q':
 				// Allow quit from any mode
 				return m, tea.Quit
+			case 'c':
+				// Copy the picked link
+				if m.mode == ContentMode && m.link >= 0 && m.clipboard != nil {
+					m.copied = m.clipboard(LinkValue(m.links[m.link])) == nil
+					return m, nil
+				}
+			default:
+				// Per-command shortcuts, such as 'h' for help
+				if m.mode == MenuMode {
+					if cmd, ok := m.menu.SelectShortcut(r); ok {
+						m.runCommand(cmd, nil)
+						return m, nil
+					}
+				}
 			}
 		}
 
@@ -80,32 +230,100 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return m, tea.Quit
 
 		case "esc", "backspace":
-			// Return to menu from content view
+			// Return to the view a choice was opened from, or to the menu
 			if m.mode == ContentMode {
-				m.mode = MenuMode
-				m.selectedCmd = nil
+				m.back()
 			}
 			return m, nil
 
 		case "up", "k":
-			// Navigate up in menu
-			if m.mode == MenuMode && m.cursor > 0 {
-				m.cursor--
+			// Navigate up in menu, pick the previous entry or scroll the output
+			switch {
+			case m.mode == MenuMode:
+				m.menu.Up()
+			case len(m.choices) > 0:
+				m.pickChoice(m.choice - 1)
+			default:
+				m.scrollBy(-1)
 			}
 			return m, nil
 
 		case "down", "j":
-			// Navigate down in menu
-			if m.mode == MenuMode && m.cursor < len(m.commands)-1 {
-				m.cursor++
+			// Navigate down in menu, pick the next entry or scroll the output
+			switch {
+			case m.mode == MenuMode:
+				m.menu.Down()
+			case len(m.choices) > 0:
+				m.pickChoice(m.choice + 1)
+			default:
+				m.scrollBy(1)
+			}
+			return m, nil
+
+		case "pgup":
+			if m.mode == ContentMode {
+				m.scrollBy(-m.contentRows())
+			}
+			return m, nil
+
+		case "pgdown":
+			if m.mode == ContentMode {
+				m.scrollBy(m.contentRows())
+			}
+			return m, nil
+
+		case "home", "g":
+			if m.mode == MenuMode {
+				m.menu.Top()
+			} else {
+				m.scrollBy(-m.scroll)
+			}
+			return m, nil
+
+		case "end", "G":
+			if m.mode == MenuMode {
+				m.menu.Bottom()
+			} else {
+				m.scrollBy(m.maxScroll())
+			}
+			return m, nil
+
+		case "tab", "shift+tab":
+			// Pick the next or previous link in the output
+			if m.mode == ContentMode && len(m.links) > 0 {
+				step := 1
+				if msg.String() == "shift+tab" {
+					step = len(m.links) - 1
+				}
+				if m.link < 0 && step > 1 {
+					m.link = 0
+				}
+				m.link = (m.link + step) % len(m.links)
+				m.copied = false
+			}
+			return m, nil
+
+		case "left":
+			// Fold the section under the cursor
+			if m.mode == MenuMode {
+				m.menu.Fold(true)
+			}
+			return m, nil
+
+		case "right":
+			// Unfold the section under the cursor
+			if m.mode == MenuMode {
+				m.menu.Fold(false)
 			}
 			return m, nil
 
 		case "enter", " ":
-			// Select command
+			// Select command, toggle a section from its header or open the
+			// picked entry of the output
 			if m.mode == MenuMode {
-				m.selectedCmd = &m.commands[m.cursor]
-				m.mode = ContentMode
+				m.activate()
+			} else if m.choice >= 0 {
+				m.openChoice()
 			}
 			return m, nil
 		}
@@ -114,123 +332,649 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	return m, nil
 }
 
+// activate runs the selected command, or folds or unfolds the selected
+// section header.
+func (m *Model) activate() {
+	item, ok := m.menu.Selected()
+	switch {
+	case !ok:
+	case item.Kind == MenuHeader:
+		m.menu.Fold(!m.menu.Collapsed(item.Section.Category.ID))
+	case item.Kind == MenuCommand:
+		m.runCommand(item.Command, nil)
+	}
+}
+
+// back returns to the content view the current one was opened from, or
+// leaves the content view for the menu.
+func (m *Model) back() {
+	if n := len(m.history); n > 0 {
+		v, history := m.history[n-1], m.history[:n-1]
+		m.runCommand(v.cmd, v.args)
+		m.history = history
+		m.choice = v.choice
+		m.scrollBy(v.scroll)
+		return
+	}
+	m.mode = MenuMode
+	m.selectedCmd = nil
+	m.output = ""
+	m.links = nil
+	m.link = -1
+	m.scroll = 0
+	m.args = nil
+	m.choices = nil
+	m.choice = -1
+}
+
+// pickChoice picks the i-th entry of the output, staying within them, and
+// scrolls it into view.
+func (m *Model) pickChoice(i int) {
+	if m.choice < 0 && i < 0 {
+		i = len(m.choices) - 1
+	}
+	m.choice = min(max(i, 0), len(m.choices)-1)
+
+	// Keep the entry's line on screen, along with the lines that follow it
+	rows := m.contentRows()
+	for n, line := range strings.Split(ansi.Strip(m.output), "\n") {
+		if strings.Contains(line, m.choices[m.choice].Label) {
+			if n < m.scroll || n >= m.scroll+rows {
+				m.scrollBy(n - m.scroll - rows/3)
+			}
+			break
+		}
+	}
+}
+
+// openChoice runs the command line of the picked entry. ESC comes back to
+// the current view with the same entry picked.
+func (m *Model) openChoice() {
+	c := m.choices[m.choice]
+	cmd, ok := FindCommand(c.Args[0])
+	if !ok || m.selectedCmd == nil {
+		return
+	}
+	v := visit{cmd: *m.selectedCmd, args: m.args, choice: m.choice, scroll: m.scroll}
+	history := append(m.history, v)
+	m.runCommand(cmd, c.Args[1:])
+	m.history = history
+}
+
+// updateMouse handles clicks, hover and the wheel. Clicks act on the zones
+// recorded by the last View, so they hit what is on screen.
+func (m Model) updateMouse(msg tea.MouseMsg) (tea.Model, tea.Cmd) {
+	if m.prompting {
+		return m, nil
+	}
+
+	switch msg.Button {
+	case tea.MouseButtonWheelUp, tea.MouseButtonWheelDown:
+		step := 1
+		if msg.Button == tea.MouseButtonWheelUp {
+			step = -1
+		}
+		switch {
+		case m.mode == ContentMode:
+			m.scrollBy(3 * step)
+		case m.mode == ChatMode:
+			m.scrollChat(-3 * step)
+		case m.mode != MenuMode:
+		case step < 0:
+			m.menu.Up()
+		default:
+			m.menu.Down()
+		}
+		return m, nil
+	}
+
+	zone, _ := m.zones.At(msg.X, msg.Y)
+	switch msg.Action {
+	case tea.MouseActionMotion:
+		m.hover = zone.ID
+	case tea.MouseActionPress:
+		if msg.Button == tea.MouseButtonLeft {
+			m.click(zone.ID)
+		}
+	}
+	return m, nil
+}
+
+// click acts on the zone with the given ID: menu rows are selected and
+// opened, "back" returns to the menu and links are picked.
+func (m *Model) click(id string) {
+	kind, arg, _ := strings.Cut(id, ":")
+	if m.mode == FormMode {
+		m.clickForm(kind, arg)
+		return
+	}
+	switch kind {
+	case "menu":
+		if i, err := strconv.Atoi(arg); err == nil && m.mode == MenuMode && m.menu.SelectItem(i) {
+			m.activate()
+		}
+	case "back":
+		switch m.mode {
+		case ContentMode:
+			m.back()
+		case ChatMode:
+			m.leaveChat()
+		}
+	case "choice":
+		if i, err := strconv.Atoi(arg); err == nil && m.mode == ContentMode && i < len(m.choices) {
+			m.choice = i
+			m.openChoice()
+		}
+	case "link":
+		for i, link := range m.links {
+			if link.ID == arg {
+				m.link = i
+				m.copied = false
+			}
+		}
+	}
+}
+
+// contentRows is how many lines of output fit between the parts of the
+// content view that stay put. With an unknown height everything is shown.
+func (m Model) contentRows() int {
+	if m.height <= 0 {
+		return 1 << 30
+	}
+	fixed := m.renderTop() + m.renderFooter(0) + m.renderPrompt()
+	rows := m.height - strings.Count(fixed, "\n")
+	return max(rows, 1)
+}
+
+// maxScroll is the furthest the output can be scrolled.
+func (m Model) maxScroll() int {
+	return max(strings.Count(m.output, "\n")+1-m.contentRows(), 0)
+}
+
+// scrollBy scrolls the output by n lines, staying within it.
+func (m *Model) scrollBy(n int) {
+	m.scroll = min(max(m.scroll+n, 0), m.maxScroll())
+}
+
+// updatePrompt handles keys while the command prompt is open
+func (m Model) updatePrompt(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	m.completions = nil
+	switch msg.Type {
+	case tea.KeyCtrlC:
+		return m, tea.Quit
+	case tea.KeyEsc:
+		m.prompting = false
+		m.input = ""
+	case tea.KeyEnter:
+		m.prompting = false
+		m.submitPrompt()
+	case tea.KeyBackspace:
+		if r := []rune(m.input); len(r) > 0 {
+			m.input = string(r[:len(r)-1])
+		}
+	case tea.KeyTab:
+		m.complete()
+	case tea.KeySpace:
+		m.input += " "
+	case tea.KeyRunes:
+		m.input += string(msg.Runes)
+	}
+	return m, nil
+}
+
+// complete expands the command name being typed at the prompt. A single
+// match is completed in full; otherwise the input grows to the longest
+// common prefix and the candidates are listed below the prompt.
+func (m *Model) complete() {
+	if strings.Contains(m.input, " ") {
+		return
+	}
+	matches := CompleteCommand(m.commands, m.input)
+	switch len(matches) {
+	case 0:
+		return
+	case 1:
+		m.input = matches[0].Name + " "
+		return
+	}
+	prefix := matches[0].Name
+	for _, cmd := range matches[1:] {
+		for !strings.HasPrefix(cmd.Name, prefix) {
+			prefix = prefix[:len(prefix)-1]
+		}
+	}
+	m.input = prefix
+	for _, cmd := range matches {
+		m.completions = append(m.completions, cmd.Name)
+	}
+}
+
+// submitPrompt runs the command typed at the prompt, e.g. "theme amber"
+func (m *Model) submitPrompt() {
+	fields := strings.Fields(m.input)
+	m.input = ""
+	if len(fields) == 0 {
+		return
+	}
+
+	if cmd, ok := FindCommand(fields[0]); ok {
+		m.menu.SelectCommand(cmd.Name)
+		m.runCommand(cmd, fields[1:])
+		return
+	}
+
+	m.selectedCmd = nil
+	m.output = m.styles.Error.Render(m.catalog.T("error.unknown_command", fields[0]))
+	m.mode = ContentMode
+	m.scroll = 0
+	m.links = nil
+	m.link = -1
+	m.args = nil
+	m.choices = nil
+	m.choice = -1
+	m.history = nil
+}
+
+// runCommand executes cmd once and keeps its output for the content view.
+// Commands may change session settings such as the theme or language, so the
+// styles and catalog are taken back from the context afterwards. Running a
+// command forgets the views left by opening choices; openChoice and back
+// restore them.
+func (m *Model) runCommand(cmd Command, args []string) {
+	ctx := &CommandContext{
+		Args:      args,
+		User:      m.user,
+		Format:    FormatStyled,
+		Styles:    m.styles,
+		Catalog:   m.catalog,
+		Clipboard: m.clipboard,
+		Width:     m.width,
+		// Pixels drawn by sixel or kitty would stay behind when the view
+		// redraws, so the TUI uses text blocks
+		Images:   ImageBlocks,
+		Dir:      m.dir,
+		Key:      m.key,
+		Session:  m.session,
+		Sessions: m.session.Registry(),
+	}
+	if m.session != nil {
+		m.session.SetView(cmd.Name) // before running, so `who` shows it
+	}
+	output, err := cmd.Run(ctx)
+	m.welcomeShown = true
+	m.dir = ctx.Dir
+	m.styles = ctx.Styles
+	m.catalog = ctx.Catalog
+	if err != nil {
+		output = m.styles.Error.Render(m.catalog.T("error.prefix", err.Error()))
+	}
+
+	m.selectedCmd = &cmd
+	m.output = output
+	m.mode = ContentMode
+	m.scroll = 0
+	m.links = visibleLinks(output, m.catalog.Locale)
+	m.link = -1
+	m.args = args
+	m.choices = nil
+	if err == nil {
+		m.choices = ctx.Choices
+	}
+	m.choice = -1
+	m.history = nil
+	if err == nil && ctx.Form != nil {
+		m.openForm(ctx.Form)
+	}
+	if err == nil && ctx.Chat && m.session != nil {
+		m.openChat()
+	}
+}
+
+// visibleLinks returns the portfolio links whose URL appears in output, so
+// they can be clicked or picked with Tab.
+func visibleLinks(output string, l Locale) []Link {
+	c, err := LoadContentFor(l)
+	if err != nil {
+		return nil
+	}
+	text := ansi.Strip(output)
+	var links []Link
+	for _, link := range c.Links {
+		if strings.Contains(text, link.URL) {
+			links = append(links, link)
+		}
+	}
+	return links
+}
+
 // View renders the TUI
 func (m Model) View() string {
+	top := m.renderTop()
+
+	var body, bottom string
+	switch m.mode {
+	case IntroMode:
+		body = m.renderIntro()
+	case MenuMode:
+		body = m.renderMenu()
+	case ContentMode:
+		// Show the slice of output the visitor scrolled to
+		rows := m.contentRows()
+		lines := strings.Split(m.renderContent(), "\n")
+		from := min(m.scroll, len(lines))
+		to := min(from+rows, len(lines))
+		body = strings.Join(lines[from:to], "\n")
+		bottom = m.renderFooter(rows)
+	case FormMode:
+		body = m.renderForm()
+		bottom = m.renderFormFooter()
+	case ChatMode:
+		body = m.renderChat()
+		bottom = m.renderChatFooter()
+	}
+	bottom += m.renderPrompt()
+
+	return m.zones.Scan(top+body+bottom, m.height)
+}
+
+// renderTop renders what stays above the menu and content: server notices
+// and, until the first command runs, the welcome banner.
+func (m Model) renderTop() string {
 	var sb strings.Builder
 
-	// Welcome banner on first render
-	if !m.welcomeShown {
-		m.welcomeShown = true
+	// Server-wide notices (e.g. restarts) stay pinned above everything else
+	if m.notice != "" {
+		icon := "⚠ "
+		if m.styles.Accessible {
+			icon = m.catalog.T("notice.label") + " "
+		}
+		sb.WriteString(m.styles.Notice.Render(icon + m.catalog.T(m.notice)))
+		sb.WriteString("\n\n")
+	}
+
+	// Welcome banner until the visitor moves on to a command. The intro
+	// draws its own.
+	if !m.welcomeShown && m.mode != IntroMode {
 		sb.WriteString(m.renderWelcome())
 		sb.WriteString("\n\n")
 	}
 
-	switch m.mode {
-	case MenuMode:
-		sb.WriteString(m.renderMenu())
-	case ContentMode:
-		sb.WriteString(m.renderContent())
+	return sb.String()
+}
+
+// renderPrompt renders the ':' command prompt, when open, with the
+// completion candidates below it.
+func (m Model) renderPrompt() string {
+	if !m.prompting {
+		return ""
+	}
+
+	var sb strings.Builder
+	sb.WriteString("\n")
+	if m.styles.Accessible {
+		sb.WriteString(m.catalog.T("prompt.label") + " " + m.input)
+	} else {
+		// Away from home, show where the file commands are working
+		if f, err := LoadFiles(m.catalog.Locale); err == nil && m.dir != "" && m.dir != f.Home() {
+			sb.WriteString(m.styles.Dim(f.Short(m.dir)) + " ")
+		}
+		sb.WriteString(m.styles.Prompt.Render(":") + m.input + "█")
+	}
+	if len(m.completions) > 0 {
+		sb.WriteString("\n" + m.styles.Dim(strings.Join(m.completions, "  ")))
 	}
 
 	return sb.String()
 }
 
+// banner is the logo at the top of the welcome screen.
+var banner = []string{
+	"  ██████╗ ███████╗███╗   ██╗ █████╗ ██████╗ ",
+	" ██╔════╝ ██╔════╝████╗  ██║██╔══██╗██╔══██╗",
+	" ██║  ███╗█████╗  ██╔██╗ ██║███████║██████╔╝",
+	" ██║   ██║██╔══╝  ██║╚██╗██║██╔══██║██╔══██╗",
+	" ╚██████╔╝███████╗██║ ╚████║██║  ██║██║  ██║",
+	"  ╚═════╝ ╚══════╝╚═╝  ╚═══╝╚═╝  ╚═╝╚═╝  ╚═╝",
+}
+
 // renderWelcome displays the welcome banner
 func (m Model) renderWelcome() string {
-	var sb strings.Builder
-
-	banner := []string{
-		"  ██████╗ ███████╗███╗   ██╗ █████╗ ██████╗ ",
-		" ██╔════╝ ██╔════╝████╗  ██║██╔══██╗██╔══██╗",
-		" ██║  ███╗█████╗  ██╔██╗ ██║███████║██████╔╝",
-		" ██║   ██║██╔══╝  ██║╚██╗██║██╔══██║██╔══██╗",
-		" ╚██████╔╝███████╗██║ ╚████║██║  ██║██║  ██║",
-		"  ╚═════╝ ╚══════╝╚═╝  ╚═══╝╚═╝  ╚═╝╚═╝  ╚═╝",
+	if m.styles.Accessible {
+		return m.catalog.T("welcome.accessible") + "\n" + m.catalog.T("welcome.accessible_hint") + "\n"
 	}
 
-	bannerStyle := lipgloss.NewStyle().
-		Foreground(CyanColor).
-		Bold(true)
+	var sb strings.Builder
 
 	for _, line := range banner {
-		sb.WriteString(bannerStyle.Render(line) + "\n")
+		sb.WriteString(m.styles.Banner.Render(line) + "\n")
 	}
 
 	sb.WriteString("\n")
-	sb.WriteString(lipgloss.NewStyle().
-		Foreground(PinkColor).
-		Bold(true).
-		Render("                    Welcome to my SSH Portfolio Terminal!") + "\n")
+	sb.WriteString(m.styles.Tagline.Render("                    "+m.catalog.T("welcome.tagline")) + "\n")
 	sb.WriteString("\n")
-	sb.WriteString(Dim("                Navigate with ↑↓/jk, Enter to select, 'q' to quit, ESC to go back") + "\n")
+	sb.WriteString(m.styles.Dim("                "+m.catalog.T("welcome.hint")) + "\n")
 
 	return sb.String()
 }
 
 // renderMenu displays the command menu
 func (m Model) renderMenu() string {
+	st, t := m.styles, m.catalog
+	if st.Accessible {
+		return m.renderAccessibleMenu()
+	}
 	var sb strings.Builder
 
-	sb.WriteString(MenuTitleStyle.Render(" SELECT A COMMAND "))
-	sb.WriteString("\n\n")
-
-	// Group commands by category
-	portfolioCmds := []Command{}
-	systemCmds := []Command{}
+	title := st.MenuTitle.Render(" " + strings.ToUpper(t.T("menu.title")) + " ")
+	var status []string
+	if m.online > 0 {
+		// Live count of the visitors browsing, see `who`
+		status = append(status, st.Prompt.Render("●")+" "+st.Dim(t.T("presence.online", m.online)))
+	}
+	if m.recording {
+		status = append(status, st.Error.Render("⏺")+" "+st.Dim(t.T("record.on")))
+	}
+	if len(status) > 0 {
+		title = lipgloss.JoinHorizontal(lipgloss.Center, title, "  "+strings.Join(status, "  "))
+	}
+	sb.WriteString(title + "\n")
+
+	for i, item := range m.menu.Items() {
+		selected := i == m.menu.Cursor()
+		id := "menu:" + strconv.Itoa(i)
+		switch item.Kind {
+		case MenuSeparator:
+			sb.WriteString("\n")
+
+		case MenuHeader:
+			// Section header, with a count of the commands folded away
+			cat := item.Section.Category
+			title := cat.Icon + " " + t.CategoryTitle(cat)
+			if m.menu.Collapsed(cat.ID) {
+				title += " " + st.Dim(t.T("menu.collapsed", len(item.Section.Commands)))
+			}
+			if selected {
+				sb.WriteString(zoneMark(id, st.SelectedItem.UnsetPaddingLeft().Render("▸ ")+st.Label(title)) + "\n")
+			} else {
+				sb.WriteString(zoneMark(id, "  "+st.Label(title)) + "\n")
+			}
 
-	for _, cmd := range m.commands {
-		if cmd.Category == "portfolio" {
-			portfolioCmds = append(portfolioCmds, cmd)
-		} else {
-			systemCmds = append(systemCmds, cmd)
+		case MenuCommand:
+			cmd := item.Command
+			line := fmt.Sprintf("%s - %s", cmd.Name, t.Describe(cmd))
+			shortcut := ""
+			if item.Shortcut != 0 {
+				shortcut = " " + st.Dim("["+string(item.Shortcut)+"]")
+			}
+			switch {
+			case item.Disabled:
+				sb.WriteString(st.NormalItem.Render(st.Dim("  "+line)) + "\n")
+			case selected:
+				sb.WriteString(zoneMark(id, st.SelectedItem.Render("▸ "+line)+shortcut) + "\n")
+			case m.hover == id:
+				sb.WriteString(zoneMark(id, st.HoverItem.Render("  "+line)+shortcut) + "\n")
+			default:
+				sb.WriteString(zoneMark(id, st.NormalItem.Render("  "+line)+shortcut) + "\n")
+			}
 		}
 	}
 
-	// Render portfolio commands
-	sb.WriteString(Label("Portfolio Commands:") + "\n")
-	currentIdx := 0
-	for _, cmd := range portfolioCmds {
-		if currentIdx == m.cursor {
-			sb.WriteString(SelectedItemStyle.Render(fmt.Sprintf("▸ %s - %s", cmd.Name, cmd.Description)) + "\n")
-		} else {
-			sb.WriteString(NormalItemStyle.Render(fmt.Sprintf("  %s - %s", cmd.Name, cmd.Description)) + "\n")
+	sb.WriteString("\n")
+	sb.WriteString(st.Help.Render(t.T("menu.help")))
+
+	return sb.String()
+}
+
+// renderContent displays the selected command output
+func (m Model) renderContent() string {
+	if m.selectedCmd == nil && m.output == "" {
+		return m.catalog.T("content.none")
+	}
+	st := m.styles
+
+	// Display the output captured when the command ran, with its links
+	// made clickable
+	body := m.output
+	for i, link := range m.links {
+		text := link.URL
+		switch {
+		case i == m.link:
+			text = st.LinkSelected.Render(text)
+		case m.hover == "link:"+link.ID:
+			text = st.LinkHover.Render(text)
 		}
-		currentIdx++
+		body = replaceText(body, link.URL, zoneMark("link:"+link.ID, text))
+	}
+	// and its entries openable
+	for i, c := range m.choices {
+		id := "choice:" + strconv.Itoa(i)
+		text := c.Label
+		switch {
+		case i == m.choice:
+			text = st.LinkSelected.Render(text)
+		case m.hover == id:
+			text = st.LinkHover.Render(text)
+		}
+		body = replaceText(body, c.Label, zoneMark(id, text))
 	}
 
-	sb.WriteString("\n")
+	return body
+}
 
-	// Render system commands
-	sb.WriteString(Label("System Commands:") + "\n")
-	for _, cmd := range systemCmds {
-		if currentIdx == m.cursor {
-			sb.WriteString(SelectedItemStyle.Render(fmt.Sprintf("▸ %s - %s", cmd.Name, cmd.Description)) + "\n")
+// renderFooter renders the line under the output, with the back button,
+// the scroll position when only rows lines of output fit, and the picked
+// link, followed by the key help.
+func (m Model) renderFooter(rows int) string {
+	if m.selectedCmd == nil && m.output == "" {
+		return ""
+	}
+	st, t := m.styles, m.catalog
+
+	// The status line is always there, even when empty, so the output
+	// keeps the same height while links are picked and unpicked
+	var status []string
+	if !st.Accessible {
+		back := st.Prompt.Render(t.T("content.back"))
+		if m.hover == "back" {
+			back = st.LinkHover.Inherit(st.Prompt).Render(t.T("content.back"))
+		}
+		status = append(status, "  "+zoneMark("back", back))
+	}
+	if lines := strings.Count(m.output, "\n") + 1; rows > 0 && lines > rows {
+		status = append(status, st.Dim(t.T("content.scroll", m.scroll+1, min(m.scroll+rows, lines), lines)))
+	}
+	if m.link >= 0 && m.link < len(m.links) {
+		link := m.links[m.link]
+		if m.copied {
+			status = append(status, st.Label(t.T("content.copied", link.Name)))
 		} else {
-			sb.WriteString(NormalItemStyle.Render(fmt.Sprintf("  %s - %s", cmd.Name, cmd.Description)) + "\n")
+			status = append(status, st.Dim(t.T("content.link", link.Name, LinkValue(link))))
 		}
-		currentIdx++
+	} else if len(m.links) > 0 {
+		status = append(status, st.Dim(t.T("content.links_hint")))
+	}
+	if m.choice >= 0 && m.choice < len(m.choices) {
+		status = append(status, st.Label(t.T("content.choice", m.choices[m.choice].Label, m.choice+1, len(m.choices))))
+	} else if len(m.choices) > 0 {
+		status = append(status, st.Dim(t.T("content.choices_hint")))
 	}
 
+	help := t.T("content.help")
+	if len(m.history) > 0 {
+		help = t.T("content.help_back")
+	}
+	var sb strings.Builder
+	sb.WriteString("\n\n")
+	sb.WriteString(strings.Join(status, "  "))
 	sb.WriteString("\n")
-	sb.WriteString(HelpStyle.Render("Press 'h' for help, 'q' to quit"))
+	sb.WriteString(st.Help.Render(help))
 
 	return sb.String()
 }
 
-// renderContent displays the selected command output
-func (m Model) renderContent() string {
-	if m.selectedCmd == nil {
-		return "No command selected"
-	}
-
+// renderAccessibleMenu lists the sections and commands as lines without
+// decoration, marks the selection in words and ends with an announcement of
+// the selected row, so a screen reader speaks each selection change.
+func (m Model) renderAccessibleMenu() string {
+	t := m.catalog
 	var sb strings.Builder
 
-	// Execute the command and display output
-	output := m.selectedCmd.Execute()
-	sb.WriteString(output)
+	sb.WriteString(t.T("menu.count", len(m.commands)) + "\n")
+	if m.online > 0 {
+		sb.WriteString(t.T("presence.online", m.online) + "\n")
+	}
+	if m.recording {
+		sb.WriteString(t.T("record.on") + "\n")
+	}
+	n := 0
+	for i, item := range m.menu.Items() {
+		selected := ""
+		if i == m.menu.Cursor() {
+			selected = t.T("menu.selected")
+		}
+		switch item.Kind {
+		case MenuHeader:
+			cat := item.Section.Category
+			sb.WriteString(fmt.Sprintf("\n%s, %s, %s%s:\n", t.CategoryTitle(cat), m.sectionState(cat.ID),
+				t.T("menu.count_section", len(item.Section.Commands)), selected))
+		case MenuCommand:
+			n++
+			sb.WriteString(fmt.Sprintf("%d. %s: %s%s%s\n", n, item.Command.Name, t.Describe(item.Command),
+				m.itemNote(item), selected))
+		}
+	}
 
-	sb.WriteString("\n\n")
-	sb.WriteString(HelpStyle.Render("Press ESC or Backspace to return to menu, 'q' to quit"))
+	sb.WriteString("\n")
+	if item, ok := m.menu.Selected(); ok {
+		pos, total := m.menu.Position()
+		if item.Kind == MenuHeader {
+			sb.WriteString(t.T("menu.announce_section", t.CategoryTitle(item.Section.Category),
+				m.sectionState(item.Section.Category.ID), pos, total) + "\n")
+		} else {
+			sb.WriteString(t.T("menu.announce", item.Command.Name, pos, total, t.Describe(item.Command)) + "\n")
+		}
+	}
+	sb.WriteString(t.T("menu.help_accessible"))
 
 	return sb.String()
 }
+
+// sectionState names whether a section is folded, for screen readers.
+func (m Model) sectionState(id string) string {
+	if m.menu.Collapsed(id) {
+		return m.catalog.T("menu.folded")
+	}
+	return m.catalog.T("menu.expanded")
+}
+
+// itemNote spells out what the styled menu shows with color and brackets:
+// the item's shortcut, or that it is unavailable.
+func (m Model) itemNote(item MenuItem) string {
+	switch {
+	case item.Disabled:
+		return m.catalog.T("menu.disabled")
+	case item.Shortcut != 0:
+		return m.catalog.T("menu.shortcut", string(item.Shortcut))
+	}
+	return ""
+}
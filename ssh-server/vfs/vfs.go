@@ -0,0 +1,135 @@
+// Package vfs is a small read-only filesystem held in memory, for the shell
+// commands of the SSH server. It is a tree of directories and text files
+// addressed by slash-separated paths, with a home directory that ~ stands
+// for. Each session keeps its own working directory and resolves paths
+// against it; the tree itself is shared.
+package vfs
+
+import (
+	"errors"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+)
+
+// Errors returned by Lookup. ErrNotExist is the standard library's, so
+// errors.Is(err, fs.ErrNotExist) works too.
+var (
+	ErrNotExist = fs.ErrNotExist
+	ErrNotDir   = errors.New("not a directory")
+	ErrIsDir    = errors.New("is a directory")
+)
+
+// Node is a directory or a text file.
+type Node struct {
+	name     string
+	dir      bool
+	content  string
+	children map[string]*Node
+}
+
+// Dir returns a directory holding children.
+func Dir(name string, children ...*Node) *Node {
+	n := &Node{name: name, dir: true, children: map[string]*Node{}}
+	return n.Add(children...)
+}
+
+// File returns a file holding content.
+func File(name, content string) *Node {
+	return &Node{name: name, content: content}
+}
+
+// Add puts children in directory n, replacing any of the same name, and
+// returns n.
+func (n *Node) Add(children ...*Node) *Node {
+	for _, c := range children {
+		n.children[c.name] = c
+	}
+	return n
+}
+
+// Name returns the node's name within its directory.
+func (n *Node) Name() string { return n.name }
+
+// IsDir reports whether n is a directory.
+func (n *Node) IsDir() bool { return n.dir }
+
+// Content returns the text of a file, and "" for a directory.
+func (n *Node) Content() string { return n.content }
+
+// Children returns the entries of a directory sorted by name, and nil for a
+// file.
+func (n *Node) Children() []*Node {
+	if !n.dir {
+		return nil
+	}
+	children := make([]*Node, 0, len(n.children))
+	for _, c := range n.children {
+		children = append(children, c)
+	}
+	sort.Slice(children, func(i, j int) bool { return children[i].name < children[j].name })
+	return children
+}
+
+// FS is a tree of nodes with a home directory.
+type FS struct {
+	root *Node
+	home string
+}
+
+// New returns a filesystem rooted at root, whose home directory is the
+// absolute path home.
+func New(root *Node, home string) *FS {
+	return &FS{root: root, home: path.Clean("/" + home)}
+}
+
+// Home returns the absolute path of the home directory.
+func (f *FS) Home() string { return f.home }
+
+// Resolve turns p into a clean absolute path. Relative paths start at the
+// absolute path dir, and "~" or a leading "~/" stand for the home
+// directory. An empty p is the home directory, as for cd. ".." at the root
+// stays at the root. Whether the result exists is up to Lookup.
+func (f *FS) Resolve(dir, p string) string {
+	switch {
+	case p == "" || p == "~":
+		return f.home
+	case strings.HasPrefix(p, "~/"):
+		p = f.home + p[1:]
+	case !strings.HasPrefix(p, "/"):
+		p = dir + "/" + p
+	}
+	return path.Clean("/" + p)
+}
+
+// Lookup returns the node at the absolute path p.
+func (f *FS) Lookup(p string) (*Node, error) {
+	n := f.root
+	for _, name := range strings.Split(strings.Trim(path.Clean("/"+p), "/"), "/") {
+		if name == "" {
+			continue
+		}
+		if !n.dir {
+			return nil, ErrNotDir
+		}
+		next, ok := n.children[name]
+		if !ok {
+			return nil, ErrNotExist
+		}
+		n = next
+	}
+	return n, nil
+}
+
+// Short returns the absolute path p with the home directory written as ~,
+// the way shell prompts show it.
+func (f *FS) Short(p string) string {
+	switch {
+	case p == f.home:
+		return "~"
+	case strings.HasPrefix(p, f.home+"/"):
+		return "~" + p[len(f.home):]
+	}
+	return p
+}
@@ -0,0 +1,93 @@
+package vfs
+
+import (
+	"errors"
+	"testing"
+)
+
+func testFS() *FS {
+	return New(Dir("/",
+		Dir("home", Dir("genar",
+			Dir("projects", File("site.md", "# Site")),
+			File("about.md", "# About"),
+		)),
+		Dir("etc", File("hostname", "genar.me")),
+	), "/home/genar")
+}
+
+func TestResolve(t *testing.T) {
+	f := testFS()
+	tests := []struct {
+		dir, p, want string
+	}{
+		{"/home/genar", "", "/home/genar"},
+		{"/etc", "~", "/home/genar"},
+		{"/etc", "~/projects", "/home/genar/projects"},
+		{"/home/genar", "projects/../about.md", "/home/genar/about.md"},
+		{"/home/genar/projects", "..", "/home/genar"},
+		{"/home/genar/projects", "../../..", "/"},
+		{"/", "..", "/"},
+		{"/home", "./genar/", "/home/genar"},
+		{"/home/genar", "/etc//hostname", "/etc/hostname"},
+		{"/home/genar", "~genar", "/home/genar/~genar"},
+	}
+	for _, tt := range tests {
+		if got := f.Resolve(tt.dir, tt.p); got != tt.want {
+			t.Errorf("Resolve(%q, %q) = %q, want %q", tt.dir, tt.p, got, tt.want)
+		}
+	}
+}
+
+func TestLookup(t *testing.T) {
+	f := testFS()
+	tests := []struct {
+		p    string
+		dir  bool
+		err  error
+		name string
+	}{
+		{p: "/", dir: true, name: "/"},
+		{p: "/home/genar/projects", dir: true, name: "projects"},
+		{p: "/etc/hostname", name: "hostname"},
+		{p: "/usr", err: ErrNotExist},
+		{p: "/etc/hostname/x", err: ErrNotDir},
+	}
+	for _, tt := range tests {
+		n, err := f.Lookup(tt.p)
+		if !errors.Is(err, tt.err) {
+			t.Errorf("Lookup(%q) error = %v, want %v", tt.p, err, tt.err)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+		if n.Name() != tt.name || n.IsDir() != tt.dir {
+			t.Errorf("Lookup(%q) = %s (dir %t), want %s (dir %t)", tt.p, n.Name(), n.IsDir(), tt.name, tt.dir)
+		}
+	}
+}
+
+func TestChildrenSorted(t *testing.T) {
+	n, _ := testFS().Lookup("/home/genar")
+	var names []string
+	for _, c := range n.Children() {
+		names = append(names, c.Name())
+	}
+	if got := len(names); got != 2 || names[0] != "about.md" || names[1] != "projects" {
+		t.Errorf("Children() = %v, want [about.md projects]", names)
+	}
+}
+
+func TestShort(t *testing.T) {
+	f := testFS()
+	for p, want := range map[string]string{
+		"/home/genar":          "~",
+		"/home/genar/projects": "~/projects",
+		"/home/genarx":         "/home/genarx",
+		"/etc":                 "/etc",
+	} {
+		if got := f.Short(p); got != want {
+			t.Errorf("Short(%q) = %q, want %q", p, got, want)
+		}
+	}
+}
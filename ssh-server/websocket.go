@@ -2,24 +2,23 @@ package main
 
 import (
 	"encoding/json"
-	"fmt"
+	"errors"
+	"io"
 	"net"
 	"net/http"
+	"strings"
 	"sync"
 	"time"
+	"unicode/utf8"
 
+	"github.com/aymanbagabas/go-osc52/v2"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/log"
+	"github.com/charmbracelet/x/ansi"
 	"github.com/gorilla/websocket"
+	"github.com/muesli/termenv"
 )
 
-func min(a, b int) int {
-	if a < b {
-		return a
-	}
-	return b
-}
-
 var upgrader = websocket.Upgrader{
 	CheckOrigin: func(r *http.Request) bool {
 		// Allow all origins for now (can be restricted in production)
@@ -43,26 +42,48 @@ type WebSocketMessage struct {
 
 // WebSocketSession handles a WebSocket connection and bridges it to Bubble Tea
 type WebSocketSession struct {
-	conn   *websocket.Conn
-	model  tea.Model
-	program *tea.Program
-	output chan []byte
-	done   chan struct{}
-	mu     sync.Mutex
-	width  int
-	height int
-	logger *log.Logger
+	id         string
+	conn       *websocket.Conn
+	model      tea.Model
+	program    *tea.Program
+	output     chan []byte
+	done       chan struct{}
+	mu         sync.Mutex
+	width      int
+	height     int
+	profile    termenv.Profile
+	accessible bool
+	hyperlinks bool
+	clipboard  bool // the client handles "clipboard" messages itself
+	locale     Locale
+	tracked    *Session  // the session in the registry, for presence
+	recorder   *Recorder // records the rendered views, nil when not recorded
+	lastView   string    // the frame sent last, not sent again unchanged
+	logs       *Logging
+	logger     *log.Logger
+
+	closeOnce sync.Once
+
+	inputSampler  *Sampler
+	renderSampler *Sampler
 }
 
-// NewWebSocketSession creates a new WebSocket session
-func NewWebSocketSession(conn *websocket.Conn, logger *log.Logger) *WebSocketSession {
+// NewWebSocketSession creates a new WebSocket session rendering with the
+// given color profile. Every log line it emits carries the session ID so it
+// can be correlated with HTTP logs.
+func NewWebSocketSession(conn *websocket.Conn, id string, profile termenv.Profile, logs *Logging) *WebSocketSession {
 	return &WebSocketSession{
-		conn:   conn,
-		output: make(chan []byte, 256),
-		done:   make(chan struct{}),
-		width:  80,
-		height: 24,
-		logger: logger,
+		id:            id,
+		conn:          conn,
+		profile:       profile,
+		output:        make(chan []byte, 256),
+		done:          make(chan struct{}),
+		width:         80,
+		height:        24,
+		logs:          logs,
+		logger:        logs.Component("ws").With("session", id),
+		inputSampler:  logs.Sampler(),
+		renderSampler: logs.Sampler(),
 	}
 }
 
@@ -72,7 +93,15 @@ func (s *WebSocketSession) Start() error {
 	m := NewModel()
 	m.width = s.width
 	m.height = s.height
-	s.model = m
+	m.styles = NewStyles(MustTheme(DefaultTheme), NewSessionRenderer(io.Discard, s.profile)).WithHyperlinks(s.hyperlinks)
+	if s.accessible {
+		m.styles = m.styles.WithAccessible(true)
+	}
+	m.catalog = CatalogFor(s.locale)
+	m.clipboard = s.copy
+	m.session = s.tracked
+	m.recording = s.recorder != nil
+	s.model = m.WithIntro()
 
 	// Create Bubble Tea program (we'll manually handle updates)
 	opts := []tea.ProgramOption{
@@ -87,16 +116,24 @@ func (s *WebSocketSession) Start() error {
 	// Start goroutine to write to WebSocket
 	go s.writeToWebSocket()
 
-	// Send initial render after a short delay
+	// Turn on xterm.js mouse reporting in SGR format, with motion for hover,
+	// the same as tea.WithMouseAllMotion does for SSH clients
+	if !s.accessible {
+		s.output <- []byte(ansi.SetAnyEventMouseMode + ansi.SetSgrExtMouseMode)
+	}
+
+	// Send initial render after a short delay, then start the intro
 	time.Sleep(50 * time.Millisecond)
 	s.renderAndSend()
+	s.runCmd(s.model.Init())
 
 	return nil
 }
 
 // readFromProgram reads output from the Bubble Tea program
 func (s *WebSocketSession) readFromProgram() {
-	// Periodically render and send updates (but less frequently to avoid overwriting user input)
+	// Periodically render and send updates (but less frequently to avoid
+	// overwriting user input); frames that didn't change aren't sent
 	ticker := time.NewTicker(500 * time.Millisecond)
 	defer ticker.Stop()
 
@@ -127,19 +164,29 @@ func (s *WebSocketSession) renderAndSend() {
 		// ANSI escape codes: \x1b[2J = clear screen, \x1b[H = move to home
 		// Use \x1b[?25l to hide cursor, \x1b[?25h to show it
 		clearedView := "\x1b[2J\x1b[H\x1b[?25l" + view + "\x1b[?25h"
-		
-		s.logger.Info("Rendering view", "viewLen", len(view), "clearedLen", len(clearedView), "preview", view[:min(len(view), 100)])
-		
+		if clearedView == s.lastView {
+			return
+		}
+
+		if s.renderSampler.Allow() {
+			s.logger.Debug("Rendering view", "size", len(clearedView), "sampleEvery", s.renderSampler.Every())
+		}
+
 		// Send as text message - use non-blocking send with timeout
 		select {
 		case s.output <- []byte(clearedView):
-			s.logger.Info("View queued for sending", "size", len(clearedView))
+			s.lastView = clearedView
+			// Only rendered views are recorded, never the clipboard and
+			// other control messages sharing the output channel
+			if s.recorder != nil {
+				s.recorder.Output([]byte(clearedView))
+			}
 		case <-time.After(100 * time.Millisecond):
 			// Channel full, skip this update
 			s.logger.Warn("Output channel full, skipping render")
 		}
 	} else {
-		s.logger.Warn("View is empty, not rendering")
+		s.logger.Debug("View is empty, not rendering")
 	}
 }
 
@@ -154,8 +201,6 @@ func (s *WebSocketSession) writeToWebSocket() {
 			if err != nil {
 				s.logger.Error("Failed to write to WebSocket", "error", err)
 				return
-			} else {
-				s.logger.Debug("Sent data to WebSocket", "size", len(data))
 			}
 		case <-s.done:
 			return
@@ -166,17 +211,23 @@ func (s *WebSocketSession) writeToWebSocket() {
 // HandleInput processes input from the WebSocket
 func (s *WebSocketSession) HandleInput(data []byte) error {
 	s.mu.Lock()
-	defer s.mu.Unlock()
+	started := s.program != nil
+	s.mu.Unlock()
 
-	if s.program == nil {
-		s.logger.Warn("HandleInput called but program is nil - program may not be initialized yet", "data", string(data), "bytes", data)
+	if !started {
+		s.logger.Warn("Input received before program was initialized", "input", s.logs.Input(data))
 		return nil
 	}
 
 	// Parse input and send to program
 	key := string(data)
-	s.logger.Info("🔧 HandleInput called", "key", key, "keyQuoted", fmt.Sprintf("%q", key), "bytes", data, "len", len(data), "programIsNil", s.program == nil)
-	
+
+	// Mouse reports arrive as SGR sequences, sometimes several at once
+	if strings.HasPrefix(key, "\x1b[<") {
+		s.updateMouse(ParseSGRMouse(key))
+		return nil
+	}
+
 	// Handle special keys - convert to tea.KeyMsg format
 	// Note: Bubble Tea's KeyMsg.String() method is used for matching in the TUI
 	var msg tea.Msg
@@ -189,90 +240,52 @@ func (s *WebSocketSession) HandleInput(data []byte) error {
 		msg = tea.KeyMsg{Type: tea.KeyLeft}
 	case "\x1b[C":
 		msg = tea.KeyMsg{Type: tea.KeyRight}
+	case "\x1b[H", "\x1b[1~":
+		msg = tea.KeyMsg{Type: tea.KeyHome}
+	case "\x1b[F", "\x1b[4~":
+		msg = tea.KeyMsg{Type: tea.KeyEnd}
+	case "\x1b[5~":
+		msg = tea.KeyMsg{Type: tea.KeyPgUp}
+	case "\x1b[6~":
+		msg = tea.KeyMsg{Type: tea.KeyPgDown}
+	case "\x1b[Z":
+		msg = tea.KeyMsg{Type: tea.KeyShiftTab}
 	case "\r", "\n":
 		msg = tea.KeyMsg{Type: tea.KeyEnter}
 	case "\x1b":
 		msg = tea.KeyMsg{Type: tea.KeyEsc}
+	case "\t":
+		msg = tea.KeyMsg{Type: tea.KeyTab}
 	case "\x7f", "\b":
 		msg = tea.KeyMsg{Type: tea.KeyBackspace}
+	case "\x13":
+		msg = tea.KeyMsg{Type: tea.KeyCtrlS}
 	case "q":
 		// "q" should be sent as a rune so String() returns "q"
 		msg = tea.KeyMsg{Runes: []rune("q"), Type: tea.KeyRunes}
 	case " ":
 		msg = tea.KeyMsg{Type: tea.KeySpace}
-	case "j":
-		msg = tea.KeyMsg{Type: tea.KeyDown}
-	case "k":
-		msg = tea.KeyMsg{Type: tea.KeyUp}
 	case "h":
 		msg = tea.KeyMsg{Runes: []rune("h"), Type: tea.KeyRunes}
 	default:
-		// Regular character - send as rune
-		if len(key) > 0 {
-			// Handle single character
-			if len(key) == 1 && key[0] >= 32 && key[0] < 127 {
-				msg = tea.KeyMsg{Runes: []rune(key), Type: tea.KeyRunes}
-			} else if len(key) > 1 {
-				// Handle multi-character sequences (like escape sequences)
-				// For now, just send the first character
-				msg = tea.KeyMsg{Runes: []rune(string(key[0])), Type: tea.KeyRunes}
-			}
+		// Typed or pasted text, which may be several characters and
+		// multi-byte ones such as é, goes as one message. Other control
+		// characters, escape sequences we don't know and invalid UTF-8
+		// are dropped.
+		if key != "" && key[0] >= 32 && utf8.ValidString(key) {
+			msg = tea.KeyMsg{Runes: []rune(key), Type: tea.KeyRunes}
 		}
 	}
 
 	// Send message to program and update model
 	if msg != nil {
-		// Log the key message details
-		if keyMsg, ok := msg.(tea.KeyMsg); ok {
-			keyStr := keyMsg.String()
-			runesStr := string(keyMsg.Runes)
-			s.logger.Info("Processing key message", "key", key, "type", keyMsg.Type, "string", keyStr, "runes", runesStr, "runesLen", len(keyMsg.Runes))
-			
-			// Special debug logging for "h" and "q" keys
-			if key == "h" || key == "q" {
-				s.logger.Info("DEBUG: Special key detected", "originalKey", key, "KeyMsg.String()", keyStr, "KeyMsg.Runes", runesStr, "KeyMsg.Type", keyMsg.Type, "RunesMatch", len(keyMsg.Runes) == 1 && ((key == "h" && keyMsg.Runes[0] == 'h') || (key == "q" && keyMsg.Runes[0] == 'q')))
-			}
-		} else {
-			s.logger.Info("Processing message", "msg", msg)
+		if s.inputSampler.Allow() {
+			s.logger.Debug("Processing input", "input", s.logs.Input(data), "sampleEvery", s.inputSampler.Every())
 		}
-		
-		// Get the old view for comparison
-		oldView := s.model.View()
-		
-		// Manually update the model (since we're not using Run())
-		var cmd tea.Cmd
-		s.model, cmd = s.model.Update(msg)
-		
-		// Execute command if any (commands are executed synchronously in Bubble Tea)
-		if cmd != nil {
-			cmdRes := cmd()
-			if cmdRes != nil {
-				// Update model with command result
-				var cmd2 tea.Cmd
-				s.model, cmd2 = s.model.Update(cmdRes)
-				if cmd2 != nil {
-					cmdRes2 := cmd2()
-					if cmdRes2 != nil {
-						s.model, _ = s.model.Update(cmdRes2)
-					}
-				}
-			}
-		}
-		
-		// Get the new view
-		newView := s.model.View()
-		s.logger.Info("Model updated", "oldViewLen", len(oldView), "newViewLen", len(newView), "changed", oldView != newView)
-		
-		// Force immediate render after update (don't wait for ticker)
-		s.renderAndSend()
-		
-		// Also trigger a render after a small delay to ensure it's sent
-		go func() {
-			time.Sleep(50 * time.Millisecond)
-			s.renderAndSend()
-		}()
+
+		s.update(msg)
 	} else {
-		s.logger.Warn("No message created for input", "key", key, "bytes", data)
+		s.logger.Debug("Ignoring unrecognized input", "input", s.logs.Input(data))
 	}
 
 	return nil
@@ -281,41 +294,152 @@ func (s *WebSocketSession) HandleInput(data []byte) error {
 // HandleResize updates terminal size
 func (s *WebSocketSession) HandleResize(size TerminalSize) {
 	s.mu.Lock()
-	defer s.mu.Unlock()
-
 	s.width = size.Cols
 	s.height = size.Rows
+	s.lastView = "" // the resized terminal is drawn again, changed or not
+	s.mu.Unlock()
+	if s.recorder != nil {
+		s.recorder.Resize(size.Cols, size.Rows)
+	}
 
-	// Update model size
-	if m, ok := s.model.(*Model); ok {
-		m.width = size.Cols
-		m.height = size.Rows
+	s.update(tea.WindowSizeMsg{
+		Width:  size.Cols,
+		Height: size.Rows,
+	})
+}
+
+// Send delivers a message to the model as if it came from the program, so
+// server-side events (such as shutdown notices) reach WebSocket visitors.
+func (s *WebSocketSession) Send(msg tea.Msg) {
+	select {
+	case <-s.done:
+		return
+	default:
 	}
+	s.update(msg)
+}
+
+// update applies msg to the model and renders the result. Commands returned
+// by the model run in the background and feed their messages back in, the
+// same way tea.Program would (since we're not using Run()).
+func (s *WebSocketSession) update(msg tea.Msg) {
+	s.mu.Lock()
+	var cmd tea.Cmd
+	s.model, cmd = s.model.Update(msg)
+	s.mu.Unlock()
 
-	// Send resize message to program
-	if s.program != nil {
-		s.program.Send(tea.WindowSizeMsg{
-			Width:  size.Cols,
-			Height: size.Rows,
-		})
+	s.runCmd(cmd)
+	s.renderAndSend()
+}
+
+// updateMouse applies mouse events to the model. The pointer moving is
+// reported on every cell it crosses, so the view is only sent again when the
+// events changed it.
+func (s *WebSocketSession) updateMouse(msgs []tea.MouseMsg) {
+	s.mu.Lock()
+	before := s.model.View()
+	cmds := make([]tea.Cmd, 0, len(msgs))
+	for _, msg := range msgs {
+		var cmd tea.Cmd
+		s.model, cmd = s.model.Update(msg)
+		cmds = append(cmds, cmd)
+	}
+	changed := s.model.View() != before
+	s.mu.Unlock()
+
+	s.runCmd(tea.Batch(cmds...))
+	if changed {
 		s.renderAndSend()
 	}
 }
 
+// runCmd executes cmd asynchronously and routes its result.
+func (s *WebSocketSession) runCmd(cmd tea.Cmd) {
+	if cmd == nil {
+		return
+	}
+	go func() {
+		switch msg := cmd().(type) {
+		case nil:
+		case tea.BatchMsg:
+			for _, c := range msg {
+				s.runCmd(c)
+			}
+		case tea.QuitMsg:
+			// Closing the connection ends the read loop, which cleans up
+			s.conn.Close()
+		default:
+			s.Send(msg)
+		}
+	}()
+}
+
+// copy puts text on the client's clipboard. Clients that announced
+// clipboard support get a {"type":"clipboard","data":{"text":...}} message
+// to handle with the browser's clipboard API; the rest get OSC 52 in the
+// terminal stream, for xterm.js with its clipboard addon.
+func (s *WebSocketSession) copy(text string) error {
+	var data []byte
+	if s.clipboard {
+		msg, err := json.Marshal(WebSocketMessage{Type: "clipboard", Data: map[string]string{"text": text}})
+		if err != nil {
+			return err
+		}
+		data = msg
+	} else {
+		data = []byte(osc52.New(text).String())
+	}
+
+	select {
+	case s.output <- data:
+		return nil
+	case <-time.After(100 * time.Millisecond):
+		return errors.New("output channel full")
+	}
+}
+
+// SetClipboard records whether the client handles "clipboard" messages.
+func (s *WebSocketSession) SetClipboard(on bool) {
+	s.mu.Lock()
+	s.clipboard = on
+	s.mu.Unlock()
+}
+
 // Close closes the session
 func (s *WebSocketSession) Close() {
-	close(s.done)
-	s.mu.Lock()
-	defer s.mu.Unlock()
-	if s.conn != nil {
-		s.conn.Close()
+	s.closeOnce.Do(func() {
+		close(s.done)
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		if s.conn != nil {
+			s.conn.Close()
+		}
+	})
+}
+
+// clientAddr returns the address of the browser behind r. Behind the Fly
+// proxy every request comes from the proxy, which names the client in
+// Fly-Client-IP and at the end of X-Forwarded-For; without those headers
+// it is the address of the connection.
+func clientAddr(r *http.Request) string {
+	if ip := strings.TrimSpace(r.Header.Get("Fly-Client-IP")); ip != "" {
+		return ip
 	}
+	if forwarded := r.Header.Get("X-Forwarded-For"); forwarded != "" {
+		hops := strings.Split(forwarded, ",")
+		if ip := strings.TrimSpace(hops[len(hops)-1]); ip != "" {
+			return ip
+		}
+	}
+	return r.RemoteAddr
 }
 
 // WebSocketHandler handles WebSocket connections
-func WebSocketHandler(logger *log.Logger) http.HandlerFunc {
+func WebSocketHandler(logs *Logging, registry *SessionRegistry, recordings *Recordings) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		logger.Info("WebSocket connection attempt", "remote", r.RemoteAddr, "path", r.URL.Path)
+		id := newSessionID()
+		logger := logs.Component("ws").With("session", id)
+
 		conn, err := upgrader.Upgrade(w, r, nil)
 		if err != nil {
 			logger.Error("Failed to upgrade WebSocket", "error", err)
@@ -323,109 +447,118 @@ func WebSocketHandler(logger *log.Logger) http.HandlerFunc {
 		}
 		defer conn.Close()
 
-		logger.Info("*** WebSocket connection established successfully ***", "remote", r.RemoteAddr, "localAddr", conn.LocalAddr(), "remoteAddr", conn.RemoteAddr())
+		start := time.Now()
+		logger.Info("connect", "remote", r.RemoteAddr, "userAgent", r.UserAgent())
+
+		profile := WebSocketColorProfile(r.URL.Query())
+		logger.Debug("Detected color profile", "colors", ColorProfileName(profile))
+
+		session := NewWebSocketSession(conn, id, profile, logs)
+		session.accessible = WebSocketAccessible(r.URL.Query())
+		session.hyperlinks = WebSocketHyperlinks(r.URL.Query())
+		session.clipboard, _ = parseSwitch(r.URL.Query().Get("clipboard"))
+		session.locale = HTTPLocale(r)
+		tracked := NewSession(id, TransportWebSocket, "guest", clientAddr(r), session.Close)
+		session.tracked = tracked
+		if err := registry.Register(tracked); err != nil {
+			conn.WriteMessage(websocket.TextMessage, []byte(CatalogFor(LocaleEnglish).T(shutdownNotice)+"\r\n"))
+			return
+		}
+		defer registry.Unregister(id)
+
+		if recordings.Wanted(WebSocketRecord(r.URL.Query())) {
+			rec, err := recordings.Start(id, TransportWebSocket, session.width, session.height, "xterm-256color")
+			if err != nil {
+				logger.Error("Failed to start recording", "error", err)
+			} else {
+				session.recorder = rec
+				defer func() {
+					if err := rec.Close(); err != nil {
+						logger.Error("Failed to save recording", "error", err)
+					}
+				}()
+			}
+		}
 
-		session := NewWebSocketSession(conn, logger)
 		if err := session.Start(); err != nil {
 			logger.Error("Failed to start session", "error", err)
 			return
 		}
 		defer session.Close()
+		tracked.SetSender(session.Send)
 
-		logger.Info("*** Session started, beginning read loop ***")
-		
-		// Verify connection is the same
-		logger.Info("Connection verification", "handlerConn", fmt.Sprintf("%p", conn), "sessionConn", fmt.Sprintf("%p", session.conn), "same", conn == session.conn)
-		
-		// Test: Try to read connection state
-		logger.Info("Connection state check", "subprotocol", conn.Subprotocol(), "remoteAddr", conn.RemoteAddr())
-		
-		// Read messages from WebSocket
-		logger.Info("*** Starting message read loop ***")
-		messageCount := 0
-		lastLogTime := time.Now()
-		firstIteration := true
+		timeouts := logs.Sampler()
 		for {
 			// Set read deadline to prevent indefinite blocking
-			deadline := time.Now().Add(5 * time.Second)
-			if err := conn.SetReadDeadline(deadline); err != nil {
+			if err := conn.SetReadDeadline(time.Now().Add(5 * time.Second)); err != nil {
 				logger.Error("Failed to set read deadline", "error", err)
 				break
 			}
-			messageCount++
-			// Log first iteration and then every 2 seconds
-			if firstIteration || time.Since(lastLogTime) > 2*time.Second {
-				logger.Info("*** Waiting for message ***", "count", messageCount, "deadline", deadline.Format(time.RFC3339), "firstIteration", firstIteration)
-				lastLogTime = time.Now()
-				firstIteration = false
-			}
-			
-			// Log before ReadMessage to see if we're actually blocking here
-			logger.Debug("About to call ReadMessage", "count", messageCount, "time", time.Now().Format(time.RFC3339Nano))
+
 			messageType, data, err := conn.ReadMessage()
-			readTime := time.Now()
-			logger.Debug("ReadMessage returned", "count", messageCount, "hasError", err != nil, "hasData", len(data) > 0, "time", readTime.Format(time.RFC3339Nano))
 			if err != nil {
 				// Check if it's a timeout (expected, continue reading)
 				if netErr, ok := err.(net.Error); ok && netErr.Timeout() {
-					// Log EVERY timeout now to see if we're getting stuck
-					logger.Info("Read timeout", "count", messageCount, "error", err.Error(), "time", readTime.Format(time.RFC3339Nano))
+					if timeouts.Allow() {
+						logger.Debug("Read timeout", "sampleEvery", timeouts.Every())
+					}
 					continue
 				}
 				if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseAbnormalClosure) {
 					logger.Error("WebSocket error", "error", err)
-				} else {
-					logger.Info("WebSocket closed normally", "error", err)
 				}
 				break
 			}
 
 			// Reset read deadline since we got a message
 			conn.SetReadDeadline(time.Time{})
-			
-			logger.Info("*** RECEIVED WebSocket message ***", "count", messageCount, "type", messageType, "size", len(data), "preview", string(data[:min(len(data), 100)]))
 
 			switch messageType {
 			case websocket.TextMessage:
-				logger.Info("📨 Processing TextMessage", "dataLen", len(data), "preview", string(data[:min(len(data), 50)]), "rawBytes", data)
 				// Try to parse as JSON (for resize messages)
 				var msg WebSocketMessage
-				unmarshalErr := json.Unmarshal(data, &msg)
-				if unmarshalErr == nil && msg.Type == "resize" {
-					// This is a resize message (JSON)
-					logger.Info("✅ Received RESIZE message (JSON)", "type", msg.Type)
+				if err := json.Unmarshal(data, &msg); err == nil && msg.Type == "capabilities" {
+					// {"type":"capabilities","data":{"accessible":true,"lang":"es","clipboard":true}},
+					// e.g. when xterm.js has its screen reader mode enabled
+					if caps, ok := msg.Data.(map[string]interface{}); ok {
+						if accessible, ok := caps["accessible"].(bool); ok {
+							logger.Debug("Handling capabilities", "accessible", accessible)
+							session.Send(AccessibilityMsg{Enabled: accessible})
+						}
+						if clipboard, ok := caps["clipboard"].(bool); ok {
+							logger.Debug("Handling capabilities", "clipboard", clipboard)
+							session.SetClipboard(clipboard)
+						}
+						if lang, ok := caps["lang"].(string); ok {
+							if l, ok := ParseLocale(lang); ok {
+								logger.Debug("Handling capabilities", "locale", l)
+								session.Send(LocaleMsg{Locale: l})
+							}
+						}
+					}
+				} else if err == nil && msg.Type == "resize" {
 					if sizeData, ok := msg.Data.(map[string]interface{}); ok {
 						size := TerminalSize{
 							Cols: int(sizeData["cols"].(float64)),
 							Rows: int(sizeData["rows"].(float64)),
 						}
-						logger.Info("Handling resize", "size", size)
+						logger.Debug("Handling resize", "cols", size.Cols, "rows", size.Rows)
 						session.HandleResize(size)
-						logger.Info("Resize handled, continuing loop")
 					}
 				} else {
 					// This is raw terminal input (from AttachAddon)
-					logger.Info("🎹 Received RAW TERMINAL INPUT (not JSON)", "size", len(data), "preview", string(data[:min(len(data), 50)]), "unmarshalErr", unmarshalErr)
-					logger.Info("Calling HandleInput with raw data...")
 					if err := session.HandleInput(data); err != nil {
 						logger.Error("Error handling input", "error", err)
-					} else {
-						logger.Info("✓ HandleInput completed successfully")
 					}
 				}
 			case websocket.BinaryMessage:
 				// Handle binary input (for raw terminal data)
-				logger.Debug("Received binary message", "size", len(data))
 				if err := session.HandleInput(data); err != nil {
 					logger.Error("Error handling binary input", "error", err)
 				}
 			}
-			
-			// Log that we're continuing the loop
-			logger.Info("Finished processing message, continuing loop", "count", messageCount)
 		}
 
-		logger.Info("WebSocket connection closed")
+		logger.Info("disconnect", "remote", r.RemoteAddr, "duration", time.Since(start))
 	}
 }
-
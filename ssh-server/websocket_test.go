@@ -0,0 +1,83 @@
+package main
+
+import (
+	"io"
+	"net/http/httptest"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/muesli/termenv"
+)
+
+// newTestWebSocketSession returns a session showing the menu, without a
+// connection: its frames pile up in s.output.
+func newTestWebSocketSession() *WebSocketSession {
+	s := NewWebSocketSession(nil, "test", termenv.Ascii, NewLogging(io.Discard, DefaultLogConfig()))
+	m := NewModel()
+	m.width, m.height = s.width, s.height
+	m.catalog = CatalogFor(LocaleEnglish)
+	s.model = m
+	s.program = tea.NewProgram(m)
+	return s
+}
+
+func TestRenderAndSendSkipsUnchangedFrames(t *testing.T) {
+	s := newTestWebSocketSession()
+	s.renderAndSend()
+	s.renderAndSend()
+	if n := len(s.output); n != 1 {
+		t.Errorf("rendering an unchanged view twice sent %d frames, want 1", n)
+	}
+	s.HandleResize(TerminalSize{Cols: 80, Rows: 24})
+	if n := len(s.output); n != 2 {
+		t.Errorf("after a resize: %d frames sent, want the view drawn again", n)
+	}
+}
+
+func TestClientAddr(t *testing.T) {
+	tests := []struct {
+		fly, forwarded, want string
+	}{
+		{"", "", "192.0.2.1:1234"},
+		{"203.0.113.7", "198.51.100.1, 203.0.113.7", "203.0.113.7"},
+		{"", "198.51.100.1, 203.0.113.8", "203.0.113.8"},
+	}
+	for _, tt := range tests {
+		r := httptest.NewRequest("GET", "/ws", nil)
+		r.RemoteAddr = "192.0.2.1:1234"
+		if tt.fly != "" {
+			r.Header.Set("Fly-Client-IP", tt.fly)
+		}
+		if tt.forwarded != "" {
+			r.Header.Set("X-Forwarded-For", tt.forwarded)
+		}
+		if got := clientAddr(r); got != tt.want {
+			t.Errorf("clientAddr(Fly-Client-IP %q, X-Forwarded-For %q) = %q, want %q", tt.fly, tt.forwarded, got, tt.want)
+		}
+	}
+}
+
+func TestHandleInputText(t *testing.T) {
+	s := newTestWebSocketSession()
+	registry := NewSessionRegistry()
+	s.tracked = NewSession("test", TransportWebSocket, "guest", "", nil)
+	if err := registry.Register(s.tracked); err != nil {
+		t.Fatal(err)
+	}
+	m := s.model.(Model)
+	m.session = s.tracked
+	m.openChat()
+	s.model = m
+
+	// Accented letters arrive as several bytes, pasted text as many
+	// characters at once; both are typed whole
+	for _, input := range []string{"héllo", ",", " ", "ñ", "andú", "\r"} {
+		if err := s.HandleInput([]byte(input)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	lines := registry.Chat().Lines()
+	if l := lines[len(lines)-1]; l.Text != "héllo, ñandú" {
+		t.Errorf("chat line = %q, want what was typed", l.Text)
+	}
+}